@@ -0,0 +1,170 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrSourceNotAllowed is returned for callbacks from addresses outside the allowlist
+	ErrSourceNotAllowed = errors.New("payriff: callback source not allowed")
+	// ErrNoCallbackRanges is returned for allowlists without source ranges,
+	// which reject every callback
+	ErrNoCallbackRanges = errors.New("payriff: no callback source ranges configured")
+)
+
+// CallbackSourceRanges lists the networks Payriff delivers callbacks from.
+// Payriff does not publish these ranges, so the list ships empty; set the
+// ranges Payriff gives you here at startup or through PAYRIFF_CALLBACK_IPS
+// so every allowlist picks them up. An allowlist without ranges fails
+// closed, rejecting every callback with ErrNoCallbackRanges
+var CallbackSourceRanges []netip.Prefix
+
+// IPAllowlist rejects callback requests that do not originate from the
+// configured source ranges
+type IPAllowlist struct {
+	Ranges []netip.Prefix
+	// TrustForwardedFor enables reading the client address from
+	// X-Forwarded-For; only enable it behind a proxy that sets the header
+	TrustForwardedFor bool
+	// TrustedProxies are skipped when walking X-Forwarded-For from the right
+	TrustedProxies []netip.Prefix
+}
+
+// NewIPAllowlist creates an allowlist from CIDRs or single addresses. With no
+// arguments it uses PAYRIFF_CALLBACK_IPS (comma separated) and then
+// CallbackSourceRanges
+func NewIPAllowlist(ranges ...string) (*IPAllowlist, error) {
+	if len(ranges) == 0 {
+		if env := os.Getenv("PAYRIFF_CALLBACK_IPS"); env != "" {
+			ranges = strings.Split(env, ",")
+		}
+	}
+
+	prefixes, err := ParsePrefixes(ranges...)
+	if err != nil {
+		return nil, err
+	}
+	if len(prefixes) == 0 {
+		prefixes = CallbackSourceRanges
+	}
+	if len(prefixes) == 0 {
+		return nil, ErrNoCallbackRanges
+	}
+	return &IPAllowlist{Ranges: prefixes}, nil
+}
+
+// ParsePrefixes parses CIDRs or single addresses into prefixes
+func ParsePrefixes(ranges ...string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, r := range ranges {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		if !strings.Contains(r, "/") {
+			addr, err := netip.ParseAddr(r)
+			if err != nil {
+				return nil, fmt.Errorf("invalid callback source %q: %w", r, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid callback source %q: %w", r, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// Allowed reports whether the request originates from an allowed address
+func (a *IPAllowlist) Allowed(r *http.Request) bool {
+	return a.allowed(r.RemoteAddr, r.Header)
+}
+
+// Middleware wraps next, answering 403 to requests from other addresses,
+// and to every request when no ranges are configured
+func (a *IPAllowlist) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.Allowed(r) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// VerifyDelivery implements DeliveryVerifier
+func (a *IPAllowlist) VerifyDelivery(ctx context.Context, d *Delivery) error {
+	if len(a.Ranges) == 0 {
+		return fmt.Errorf("%w: %w", ErrSourceNotAllowed, ErrNoCallbackRanges)
+	}
+	if !a.allowed(d.RemoteAddr, d.Header) {
+		return fmt.Errorf("%w: %s", ErrSourceNotAllowed, d.RemoteAddr)
+	}
+	return nil
+}
+
+func (a *IPAllowlist) allowed(remoteAddr string, header http.Header) bool {
+	addr, ok := a.clientAddr(remoteAddr, header)
+	if !ok {
+		return false
+	}
+	return containsAddr(a.Ranges, addr)
+}
+
+// clientAddr returns the peer address, or the right-most untrusted
+// X-Forwarded-For entry when forwarded headers are trusted
+func (a *IPAllowlist) clientAddr(remoteAddr string, header http.Header) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	peer, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	peer = peer.Unmap()
+
+	if !a.TrustForwardedFor {
+		return peer, true
+	}
+	if len(a.TrustedProxies) > 0 && !containsAddr(a.TrustedProxies, peer) {
+		return peer, true
+	}
+
+	forwarded := header.Values("X-Forwarded-For")
+	if len(forwarded) == 0 {
+		return peer, true
+	}
+
+	hops := strings.Split(strings.Join(forwarded, ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		hop = hop.Unmap()
+		if !containsAddr(a.TrustedProxies, hop) {
+			return hop, true
+		}
+	}
+	return peer, true
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package payriff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRefundApprovalNotFound is returned for unknown refund approval IDs
+	ErrRefundApprovalNotFound = errors.New("payriff: refund approval not found")
+	// ErrRefundNotPending is returned when deciding a refund that was already decided
+	ErrRefundNotPending = errors.New("payriff: refund is not pending approval")
+	// ErrSelfApproval is returned when the requester tries to approve their own refund
+	ErrSelfApproval = errors.New("payriff: refund must be approved by a different operator")
+	// ErrRefundApprovalExists is returned when requesting a refund under an ID already in use
+	ErrRefundApprovalExists = errors.New("payriff: refund approval already exists")
+	// ErrNoRequester is returned when requesting a refund without an operator on the context
+	ErrNoRequester = errors.New("payriff: refund request needs an operator, set one with WithOperator")
+)
+
+// RefundApprovalState is the state of a refund awaiting approval
+type RefundApprovalState string
+
+const (
+	RefundPending RefundApprovalState = "pending"
+	// RefundExecuting is claimed by an approver while the refund is sent
+	RefundExecuting RefundApprovalState = "executing"
+	RefundRejected  RefundApprovalState = "rejected"
+	RefundExecuted  RefundApprovalState = "executed"
+	RefundFailed    RefundApprovalState = "failed"
+)
+
+// RefundApproval is a refund that runs only after a second operator approves it
+type RefundApproval struct {
+	ID          string
+	Request     RefundRequest
+	RequestedBy string
+	RequestedAt time.Time
+	State       RefundApprovalState
+	DecidedBy   string
+	DecidedAt   time.Time
+	// Reason is the rejection reason or the refund error
+	Reason string
+	// Response is the gateway response of an executed refund
+	Response json.RawMessage
+}
+
+// RefundApprovalStore persists refund approvals
+type RefundApprovalStore interface {
+	// CreateRefundApproval stores a new approval, or returns
+	// ErrRefundApprovalExists when its ID is taken
+	CreateRefundApproval(ctx context.Context, approval *RefundApproval) error
+	// ClaimRefundApproval atomically moves an approval from one state to
+	// another, returning ErrRefundNotPending when it is not in from
+	ClaimRefundApproval(ctx context.Context, id string, from, to RefundApprovalState) error
+	SaveRefundApproval(ctx context.Context, approval *RefundApproval) error
+	// LoadRefundApproval returns ErrRefundApprovalNotFound for unknown IDs
+	LoadRefundApproval(ctx context.Context, id string) (*RefundApproval, error)
+}
+
+// RefundApprovals implements a four-eyes refund flow: RequestRefund records
+// the refund and Approve by a different operator sends it to the gateway
+type RefundApprovals struct {
+	SDK   *SDK
+	Store RefundApprovalStore
+}
+
+// RequestRefund records a refund pending approval. The requester is the
+// operator set on ctx with WithOperator, and is required
+func (a *RefundApprovals) RequestRefund(ctx context.Context, id string, req RefundRequest) (*RefundApproval, error) {
+	requester := OperatorFrom(ctx)
+	if requester == "" {
+		return nil, ErrNoRequester
+	}
+
+	approval := &RefundApproval{
+		ID:          id,
+		Request:     req,
+		RequestedBy: requester,
+		RequestedAt: time.Now(),
+		State:       RefundPending,
+	}
+	if err := a.Store.CreateRefundApproval(ctx, approval); err != nil {
+		return nil, fmt.Errorf("failed to save refund approval: %w", err)
+	}
+	return approval, nil
+}
+
+// Approve executes a pending refund on behalf of approverID. The approval
+// is claimed in the store first, so concurrent approvers cannot both
+// execute it
+func (a *RefundApprovals) Approve(ctx context.Context, id, approverID string) (*RefundApproval, error) {
+	approval, err := a.pending(ctx, id, approverID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Store.ClaimRefundApproval(ctx, id, RefundPending, RefundExecuting); err != nil {
+		return nil, err
+	}
+
+	// The approval ID doubles as idempotency key, so approving twice
+	// cannot refund twice
+	ctx = WithIdempotencyKey(WithOperator(ctx, approverID), "refund-approval-"+id)
+	ctx = context.WithValue(ctx, approvedRefundCtx{}, true)
+	resp, err := a.SDK.RefundContext(ctx, approval.Request)
+	switch {
+	case err != nil:
+		approval.State, approval.Reason = RefundFailed, err.Error()
+	case !resp.IsSuccessful():
+		approval.State, approval.Reason = RefundFailed, fmt.Sprintf("%s %s", resp.Code, resp.Message)
+	default:
+		approval.State, approval.Response = RefundExecuted, resp.Payload
+	}
+
+	if serr := a.decide(ctx, approval, approverID); serr != nil {
+		return approval, serr
+	}
+	return approval, err
+}
+
+// Reject declines a pending refund
+func (a *RefundApprovals) Reject(ctx context.Context, id, approverID, reason string) (*RefundApproval, error) {
+	approval, err := a.pending(ctx, id, approverID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Store.ClaimRefundApproval(ctx, id, RefundPending, RefundRejected); err != nil {
+		return nil, err
+	}
+
+	approval.State, approval.Reason = RefundRejected, reason
+	return approval, a.decide(ctx, approval, approverID)
+}
+
+func (a *RefundApprovals) pending(ctx context.Context, id, approverID string) (*RefundApproval, error) {
+	approval, err := a.Store.LoadRefundApproval(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.State != RefundPending {
+		return nil, fmt.Errorf("%w: %s is %s", ErrRefundNotPending, id, approval.State)
+	}
+	if approverID == "" || approval.RequestedBy == "" || approverID == approval.RequestedBy {
+		return nil, ErrSelfApproval
+	}
+	return approval, nil
+}
+
+func (a *RefundApprovals) decide(ctx context.Context, approval *RefundApproval, approverID string) error {
+	approval.DecidedBy = approverID
+	approval.DecidedAt = time.Now()
+	if err := a.Store.SaveRefundApproval(ctx, approval); err != nil {
+		return fmt.Errorf("failed to save refund approval: %w", err)
+	}
+	return nil
+}
+
+// MemoryRefundApprovalStore is an in-process RefundApprovalStore
+type MemoryRefundApprovalStore struct {
+	mu        sync.Mutex
+	approvals map[string]RefundApproval
+}
+
+// CreateRefundApproval implements RefundApprovalStore
+func (m *MemoryRefundApprovalStore) CreateRefundApproval(ctx context.Context, approval *RefundApproval) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.approvals == nil {
+		m.approvals = make(map[string]RefundApproval)
+	}
+	if _, ok := m.approvals[approval.ID]; ok {
+		return fmt.Errorf("%w: %s", ErrRefundApprovalExists, approval.ID)
+	}
+	m.approvals[approval.ID] = *approval
+	return nil
+}
+
+// ClaimRefundApproval implements RefundApprovalStore
+func (m *MemoryRefundApprovalStore) ClaimRefundApproval(ctx context.Context, id string, from, to RefundApprovalState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	approval, ok := m.approvals[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRefundApprovalNotFound, id)
+	}
+	if approval.State != from {
+		return fmt.Errorf("%w: %s is %s", ErrRefundNotPending, id, approval.State)
+	}
+	approval.State = to
+	m.approvals[id] = approval
+	return nil
+}
+
+// SaveRefundApproval implements RefundApprovalStore
+func (m *MemoryRefundApprovalStore) SaveRefundApproval(ctx context.Context, approval *RefundApproval) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.approvals == nil {
+		m.approvals = make(map[string]RefundApproval)
+	}
+	m.approvals[approval.ID] = *approval
+	return nil
+}
+
+// LoadRefundApproval implements RefundApprovalStore
+func (m *MemoryRefundApprovalStore) LoadRefundApproval(ctx context.Context, id string) (*RefundApproval, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	approval, ok := m.approvals[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRefundApprovalNotFound, id)
+	}
+	return &approval, nil
+}
@@ -0,0 +1,134 @@
+package payriff
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditRecord describes one API call as sent to the gateway
+type AuditRecord struct {
+	Time     time.Time
+	Method   string
+	Endpoint string
+	// Operation is the SDK method of the endpoint, see EndpointInfo
+	Operation string
+	// MovesMoney is set for calls that charge, refund or transfer funds
+	MovesMoney bool
+	// Body is the request body with card numbers, secrets and customer
+	// contact details replaced by Redacted, see Redact
+	Body []byte
+	// BodyHash is the ContentHash of the body exactly as sent
+	BodyHash       string
+	IdempotencyKey string
+	// Operator is the acting user set with WithOperator
+	Operator string
+	// OverriddenBy is the operator whose policy override token allowed the
+	// call to exceed the AmountPolicy
+	OverriddenBy string
+	Code         ResultCode
+	// Error is set when the call failed
+	Error string
+}
+
+// AuditSink records API calls for later verification
+type AuditSink interface {
+	Record(ctx context.Context, record AuditRecord) error
+}
+
+// AuditSinkFunc adapts a function to the AuditSink interface
+type AuditSinkFunc func(ctx context.Context, record AuditRecord) error
+
+// Record calls f(ctx, record)
+func (f AuditSinkFunc) Record(ctx context.Context, record AuditRecord) error {
+	return f(ctx, record)
+}
+
+// CanonicalJSON encodes v as JSON with object keys sorted at every level,
+// no insignificant whitespace and no HTML escaping, so equal values always
+// produce identical bytes
+func CanonicalJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	// Maps encode with sorted keys
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(generic); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// ContentHash returns the hex SHA-256 of a request body
+func ContentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// encodeBody serializes a request body, canonically when configured
+func (s *SDK) encodeBody(body any) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+
+	if s.canonicalJSON {
+		payload, err := CanonicalJSON(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		return payload, nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return nil, fmt.Errorf("failed to encode request body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// audit records a finished API call with the configured sink
+func (s *SDK) audit(ctx context.Context, method, endpoint string, payload []byte, resp *Response, callErr error) {
+	if s.auditSink == nil {
+		return
+	}
+
+	record := AuditRecord{
+		Time:           time.Now(),
+		Method:         method,
+		Endpoint:       endpoint,
+		Body:           Redact(payload),
+		BodyHash:       ContentHash(payload),
+		IdempotencyKey: idempotencyKey(ctx),
+		Operator:       OperatorFrom(ctx),
+		OverriddenBy:   overriddenBy(ctx),
+	}
+	if e, ok := LookupEndpoint(method, endpoint); ok {
+		record.Operation = e.Name
+		record.MovesMoney = e.MovesMoney
+	}
+	if resp != nil {
+		record.Code = resp.Code
+	}
+	if callErr != nil {
+		record.Error = callErr.Error()
+	}
+
+	if err := safeCall(func() error { return s.auditSink.Record(ctx, record) }); err != nil {
+		s.reportError(fmt.Errorf("failed to record audit entry: %w", err))
+	}
+}
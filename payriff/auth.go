@@ -0,0 +1,184 @@
+package payriff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator attaches credentials to API requests. Set Config.Auth to
+// use it in place of the static key in the Authorization header
+type Authenticator interface {
+	Authenticate(ctx context.Context, req *http.Request) error
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface
+type AuthenticatorFunc func(ctx context.Context, req *http.Request) error
+
+// Authenticate calls f(ctx, req)
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, req *http.Request) error {
+	return f(ctx, req)
+}
+
+// Token is a bearer token issued by an authorization server
+type Token struct {
+	AccessToken string
+	TokenType   string
+	ExpiresAt   time.Time
+}
+
+// TokenAuth exchanges client credentials for a bearer token using the
+// OAuth2 client_credentials grant and sends it as the Authorization header.
+// Tokens are cached and refreshed shortly before they expire; concurrent
+// requests share a single refresh
+type TokenAuth struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// Client defaults to a client with DefaultTimeout
+	Client *http.Client
+	// RefreshBefore is how long before expiry a token is refreshed,
+	// defaults to one minute
+	RefreshBefore time.Duration
+	// RefreshTimeout bounds a background refresh, which outlives the request
+	// that started it, defaults to DefaultTimeout
+	RefreshTimeout time.Duration
+
+	mu       sync.Mutex
+	cached   *Token
+	inflight *tokenCall
+}
+
+// tokenCall is a token request shared by concurrent callers
+type tokenCall struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// Authenticate implements Authenticator
+func (a *TokenAuth) Authenticate(ctx context.Context, req *http.Request) error {
+	token, err := a.cachedToken(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return nil
+}
+
+// cachedToken returns the cached token, refreshing it when it is about to
+// expire. A token that is still valid is returned while the refresh runs
+func (a *TokenAuth) cachedToken(ctx context.Context) (*Token, error) {
+	now := time.Now()
+
+	a.mu.Lock()
+	token := a.cached
+	valid := token != nil && (token.ExpiresAt.IsZero() || now.Before(token.ExpiresAt))
+	fresh := token != nil && (token.ExpiresAt.IsZero() || now.Before(token.ExpiresAt.Add(-a.refreshBefore())))
+	if fresh {
+		a.mu.Unlock()
+		return token, nil
+	}
+
+	call := a.inflight
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		a.inflight = call
+		// The refresh outlives the request that started it, bounded by its
+		// own timeout so a hanging token endpoint cannot block every caller
+		go a.refresh(context.WithoutCancel(ctx), call)
+	}
+	a.mu.Unlock()
+
+	if valid {
+		return token, nil
+	}
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (a *TokenAuth) refresh(ctx context.Context, call *tokenCall) {
+	timeout := a.RefreshTimeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	call.token, call.err = a.Token(ctx)
+
+	a.mu.Lock()
+	if call.err == nil {
+		a.cached = call.token
+	}
+	a.inflight = nil
+	a.mu.Unlock()
+
+	close(call.done)
+}
+
+func (a *TokenAuth) refreshBefore() time.Duration {
+	if a.RefreshBefore > 0 {
+		return a.RefreshBefore
+	}
+	return time.Minute
+}
+
+// Token requests a new token from TokenURL, bypassing the cache
+func (a *TokenAuth) Token(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(a.ClientID), url.QueryEscape(a.ClientSecret))
+
+	client := a.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("payriff: token request failed: %s", resp.Status)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if body.AccessToken == "" {
+		return nil, errors.New("payriff: token response has no access token")
+	}
+
+	token := &Token{AccessToken: body.AccessToken, TokenType: body.TokenType}
+	if body.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
@@ -0,0 +1,32 @@
+package payriff
+
+// AutoPayResult is the payload of an AutoPay charge. Unlike OrderInfo it
+// describes an immediate result: there is no payment URL and the charge
+// is already approved or declined
+type AutoPayResult struct {
+	OrderID       OrderID       `json:"orderId"`
+	Amount        float64       `json:"amount"`
+	CurrencyType  Currency      `json:"currencyType"`
+	OperationType Operation     `json:"operationType"`
+	PaymentStatus Status        `json:"paymentStatus"`
+	Description   string        `json:"description"`
+	CreatedDate   string        `json:"createdDate"`
+	Transactions  []Transaction `json:"transactions,omitempty"`
+	// ResponseCode and ResponseMessage are the processor's decline details,
+	// set when the charge was not approved
+	ResponseCode    string `json:"responseCode,omitempty"`
+	ResponseMessage string `json:"responseMessage,omitempty"`
+}
+
+// Approved reports whether the charge went through
+func (r AutoPayResult) Approved() bool {
+	return r.PaymentStatus.IsSuccessful()
+}
+
+// Transaction returns the transaction created by the charge, or nil
+func (r AutoPayResult) Transaction() *Transaction {
+	if len(r.Transactions) == 0 {
+		return nil
+	}
+	return &r.Transactions[len(r.Transactions)-1]
+}
@@ -0,0 +1,122 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// OrderSink receives orders imported by a Backfill
+type OrderSink interface {
+	StoreOrders(ctx context.Context, orders []OrderInfo) error
+}
+
+// OrderSinkFunc adapts a function to the OrderSink interface
+type OrderSinkFunc func(ctx context.Context, orders []OrderInfo) error
+
+// StoreOrders calls f(ctx, orders)
+func (f OrderSinkFunc) StoreOrders(ctx context.Context, orders []OrderInfo) error {
+	return f(ctx, orders)
+}
+
+// BackfillProgress reports a batch of orders that was fully imported
+type BackfillProgress struct {
+	Orders int
+	// Last is the last order ID of the batch
+	Last OrderID
+	// Total is the number of orders imported so far
+	Total int
+}
+
+// Backfill imports historical orders into a sink. The gateway API cannot
+// list orders, so the order IDs come from the caller, e.g. the merchant's
+// own order table or a portal export. Orders are fetched one at a time,
+// pausing between requests to stay under the gateway rate limit. Resume an
+// interrupted run by skipping the IDs up to the last reported one
+type Backfill struct {
+	SDK  *SDK
+	Sink OrderSink
+	// OrderIDs yields the orders to import
+	OrderIDs iter.Seq[OrderID]
+	// BatchSize is the number of orders stored at once, defaults to 50
+	BatchSize int
+	// Interval is the minimum time between requests, defaults to 200ms
+	Interval time.Duration
+	// OnProgress is called after every batch
+	OnProgress func(BackfillProgress)
+}
+
+// Run imports every order and returns the number of orders imported
+func (b *Backfill) Run(ctx context.Context) (int, error) {
+	if b.SDK == nil || b.Sink == nil || b.OrderIDs == nil {
+		return 0, errors.New("payriff: backfill needs an SDK, a sink and order IDs")
+	}
+
+	batchSize := b.BatchSize
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+	interval := b.Interval
+	if interval <= 0 {
+		interval = 200 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// paced holds every fetch back until the next tick
+	paced := func(yield func(OrderID) bool) {
+		for id := range b.OrderIDs {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if !yield(id) {
+				return
+			}
+		}
+	}
+
+	total := 0
+	batch := make([]OrderInfo, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		last := batch[len(batch)-1].OrderID
+		if err := safeCall(func() error { return b.Sink.StoreOrders(ctx, batch) }); err != nil {
+			return fmt.Errorf("failed to store orders up to %s: %w", last, err)
+		}
+		total += len(batch)
+
+		if b.OnProgress != nil {
+			p := BackfillProgress{Orders: len(batch), Last: last, Total: total}
+			b.SDK.runHook(func() { b.OnProgress(p) })
+		}
+		batch = make([]OrderInfo, 0, batchSize)
+		return nil
+	}
+
+	for order, err := range b.SDK.Orders(ctx, paced) {
+		// A failed order would silently leave a gap, so it always stops the run
+		if err != nil {
+			if ferr := flush(); ferr != nil {
+				return total, ferr
+			}
+			return total, fmt.Errorf("failed to fetch order %s: %w", order.OrderID, err)
+		}
+
+		batch = append(batch, order)
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return total, err
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return total, err
+	}
+	return total, flush()
+}
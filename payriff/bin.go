@@ -0,0 +1,164 @@
+package payriff
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+)
+
+//go:embed data/bins.csv
+var embeddedBINs string
+
+// CardType represents the funding type of a card
+type CardType string
+
+const (
+	CardTypeDebit   CardType = "DEBIT"
+	CardTypeCredit  CardType = "CREDIT"
+	CardTypePrepaid CardType = "PREPAID"
+	CardTypeUnknown CardType = "UNKNOWN"
+)
+
+// ErrBINNotFound is returned when no source knows the requested BIN
+var ErrBINNotFound = errors.New("payriff: BIN not found")
+
+// BINInfo holds issuer details resolved from a card BIN
+type BINInfo struct {
+	BIN     string
+	Bank    string
+	Brand   string
+	Type    CardType
+	Country string
+}
+
+// BINSource is a pluggable data source for BIN lookups
+type BINSource interface {
+	LookupBIN(bin string) (BINInfo, bool)
+}
+
+// BINTable is an in-memory BINSource keyed by BIN prefix
+type BINTable map[string]BINInfo
+
+// LookupBIN returns the entry with the longest prefix matching bin
+func (t BINTable) LookupBIN(bin string) (BINInfo, bool) {
+	for n := len(bin); n >= 6; n-- {
+		if info, ok := t[bin[:n]]; ok {
+			return info, true
+		}
+	}
+	return BINInfo{}, false
+}
+
+// ParseBINTable reads a BIN table from CSV rows of bin,bank,brand,type,country
+func ParseBINTable(data string) (BINTable, error) {
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comment = '#'
+	r.FieldsPerRecord = 5
+	r.TrimLeadingSpace = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse BIN table: %w", err)
+	}
+
+	table := make(BINTable, len(records))
+	for _, rec := range records {
+		table[rec[0]] = BINInfo{
+			BIN:     rec[0],
+			Bank:    rec[1],
+			Brand:   rec[2],
+			Type:    CardType(rec[3]),
+			Country: rec[4],
+		}
+	}
+	return table, nil
+}
+
+// embeddedBINTable parses the embedded dataset once
+var embeddedBINTable = sync.OnceValues(func() (BINTable, error) {
+	table, err := ParseBINTable(embeddedBINs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse embedded BIN dataset: %w", err)
+	}
+	return table, nil
+})
+
+// DefaultBINTable returns a copy of the embedded dataset of Azerbaijani
+// issuers, which is parsed once
+func DefaultBINTable() (BINTable, error) {
+	table, err := embeddedBINTable()
+	if err != nil {
+		return nil, err
+	}
+	return maps.Clone(table), nil
+}
+
+// BINResolver resolves card BINs against a chain of sources
+type BINResolver struct {
+	sources []BINSource
+}
+
+// NewBINResolver creates a resolver that queries sources in order,
+// falling back to the embedded dataset when none are given
+func NewBINResolver(sources ...BINSource) *BINResolver {
+	return &BINResolver{sources: sources}
+}
+
+// Resolve returns issuer details for a masked or full PAN
+func (r *BINResolver) Resolve(maskedPan string) (BINInfo, error) {
+	bin := ExtractBIN(maskedPan)
+	if len(bin) < 6 {
+		return BINInfo{}, fmt.Errorf("payriff: PAN %q has no usable BIN: %w", maskedPan, ErrBINNotFound)
+	}
+
+	sources := r.sources
+	if len(sources) == 0 {
+		table, err := embeddedBINTable()
+		if err != nil {
+			return BINInfo{}, err
+		}
+		sources = []BINSource{table}
+	}
+	for _, source := range sources {
+		if info, ok := source.LookupBIN(bin); ok {
+			return info, nil
+		}
+	}
+	return BINInfo{}, fmt.Errorf("payriff: BIN %s: %w", bin, ErrBINNotFound)
+}
+
+// ResolveCard returns issuer details for saved card information
+func (r *BINResolver) ResolveCard(card CardDetails) (BINInfo, error) {
+	return r.Resolve(card.MaskedPan)
+}
+
+// ExtractBIN returns the leading digits of a PAN up to the first mask
+// character, capped at eight digits
+func ExtractBIN(pan string) string {
+	var b strings.Builder
+	for _, c := range pan {
+		switch {
+		case c >= '0' && c <= '9':
+			b.WriteRune(c)
+		case c == ' ' || c == '-':
+			continue
+		default:
+			return truncate(b.String(), 8)
+		}
+		if b.Len() == 8 {
+			break
+		}
+	}
+	return truncate(b.String(), 8)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package payriff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCallback is returned for callback bodies that cannot be parsed
+var ErrInvalidCallback = errors.New("payriff: invalid callback")
+
+// CallbackEvent is the notification Payriff posts to an order's callback URL
+type CallbackEvent struct {
+	Code       ResultCode
+	Message    string
+	ResponseID string
+
+	OrderID       OrderID
+	Amount        float64
+	Currency      Currency
+	PaymentStatus Status
+	Operation     Operation
+	Description   string
+	CreatedDate   string
+	// CardUUID is set when a card was saved
+	CardUUID     CardUUID
+	TerminalID   string
+	Customer     *Customer
+	Transactions []Transaction
+
+	// Delivery is the request the event arrived in, with its attempt
+	// metadata. It is nil for events parsed from a bare body
+	Delivery *Delivery
+}
+
+// callbackBody is the wire format of a callback
+type callbackBody struct {
+	Code       ResultCode `json:"code"`
+	Message    string     `json:"message"`
+	ResponseID string     `json:"responseId"`
+	Payload    struct {
+		OrderInfo
+		CardUUID CardUUID `json:"cardUuid"`
+	} `json:"payload"`
+}
+
+// ParseCallback decodes a callback body
+func ParseCallback(body []byte) (*CallbackEvent, error) {
+	var b callbackBody
+	if err := json.Unmarshal(body, &b); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCallback, err)
+	}
+	if b.Payload.OrderID == "" {
+		return nil, fmt.Errorf("%w: no order ID", ErrInvalidCallback)
+	}
+
+	event := &CallbackEvent{
+		Code:          b.Code,
+		Message:       b.Message,
+		ResponseID:    b.ResponseID,
+		OrderID:       b.Payload.OrderID,
+		Amount:        b.Payload.Amount,
+		Currency:      b.Payload.CurrencyType,
+		PaymentStatus: b.Payload.PaymentStatus,
+		Operation:     b.Payload.OperationType,
+		Description:   b.Payload.Description,
+		CreatedDate:   b.Payload.CreatedDate,
+		CardUUID:      b.Payload.CardUUID,
+		TerminalID:    b.Payload.TerminalID,
+		Customer:      b.Payload.Customer,
+		Transactions:  b.Payload.Transactions,
+	}
+	if event.CardUUID == "" {
+		event.CardUUID, _ = b.Payload.SavedCardUUID()
+	}
+	return event, nil
+}
+
+// ParseCallback decodes the body of a delivery as a callback
+func (d *Delivery) ParseCallback() (*CallbackEvent, error) {
+	event, err := ParseCallback(d.Body)
+	if err != nil {
+		return nil, err
+	}
+	event.Delivery = d
+	return event, nil
+}
@@ -0,0 +1,74 @@
+package payriff
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// HeaderCallbackSignature carries the HMAC-SHA256 of a callback body
+const HeaderCallbackSignature = "X-Payriff-Callback-Signature"
+
+// CallbackSignature is a DeliveryVerifier that rejects callbacks whose body
+// is not signed with a shared secret, so a leaked callback URL is not enough
+// to forge a notification. The signature is the hex or base64 HMAC-SHA256
+// of the raw body, optionally prefixed with "sha256="
+type CallbackSignature struct {
+	// Secrets holds every accepted secret, so secrets can be rotated
+	Secrets [][]byte
+	// Header defaults to HeaderCallbackSignature
+	Header string
+}
+
+// NewCallbackSignature creates a verifier accepting any of the given secrets
+func NewCallbackSignature(secrets ...string) *CallbackSignature {
+	v := &CallbackSignature{}
+	for _, secret := range secrets {
+		v.Secrets = append(v.Secrets, []byte(secret))
+	}
+	return v
+}
+
+// Sign returns the hex signature of body, e.g. for tests or relays
+func (v *CallbackSignature) Sign(body []byte) string {
+	if len(v.Secrets) == 0 {
+		return ""
+	}
+	return hex.EncodeToString(callbackMAC(v.Secrets[0], body))
+}
+
+// VerifyDelivery implements DeliveryVerifier
+func (v *CallbackSignature) VerifyDelivery(_ context.Context, d *Delivery) error {
+	header := v.Header
+	if header == "" {
+		header = HeaderCallbackSignature
+	}
+
+	signature := strings.TrimPrefix(strings.TrimSpace(d.Header.Get(header)), "sha256=")
+	if signature == "" {
+		return ErrMissingSignature
+	}
+	mac, err := hex.DecodeString(signature)
+	if err != nil {
+		if mac, err = base64.StdEncoding.DecodeString(signature); err != nil {
+			return fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+		}
+	}
+
+	for _, secret := range v.Secrets {
+		if hmac.Equal(mac, callbackMAC(secret, d.Body)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func callbackMAC(secret, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,74 @@
+package payriff
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrUnsupportedCombination is returned for requests combining an operation
+// with a currency or feature the gateway does not support
+var ErrUnsupportedCombination = errors.New("payriff: unsupported operation combination")
+
+// Capability lists what an operation supports
+type Capability struct {
+	Currencies   []Currency
+	CardSave     bool
+	Installments bool
+}
+
+// CapabilityMatrix maps operations to their capabilities
+type CapabilityMatrix map[Operation]Capability
+
+// DefaultCapabilities is the matrix used unless Config.Capabilities is set
+var DefaultCapabilities = CapabilityMatrix{
+	OperationPurchase: {
+		Currencies:   []Currency{CurrencyAZN, CurrencyUSD, CurrencyEUR},
+		CardSave:     true,
+		Installments: true,
+	},
+	OperationPreAuth: {
+		Currencies: []Currency{CurrencyAZN, CurrencyUSD, CurrencyEUR},
+		CardSave:   true,
+	},
+}
+
+// OperationParams is the combination of options a request uses
+type OperationParams struct {
+	Operation   Operation
+	Currency    Currency
+	CardSave    bool
+	Installment bool
+}
+
+// CapabilityError explains why a combination is not supported
+type CapabilityError struct {
+	Params OperationParams
+	Reason string
+}
+
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("payriff: %s not supported: %s", e.Params.Operation, e.Reason)
+}
+
+func (e *CapabilityError) Unwrap() error {
+	return ErrUnsupportedCombination
+}
+
+// Check returns a *CapabilityError when the matrix does not allow p
+func (m CapabilityMatrix) Check(p OperationParams) error {
+	c, ok := m[p.Operation]
+	if !ok {
+		return &CapabilityError{Params: p, Reason: "unknown operation"}
+	}
+	if p.Currency != "" && !slices.Contains(c.Currencies, p.Currency) {
+		return &CapabilityError{Params: p, Reason: fmt.Sprintf("currency %s is not accepted", p.Currency)}
+	}
+	if p.CardSave && !c.CardSave {
+		return &CapabilityError{Params: p, Reason: "cards cannot be saved"}
+	}
+	if p.Installment && !c.Installments {
+		return &CapabilityError{Params: p, Reason: "installments are not available"}
+	}
+	return nil
+}
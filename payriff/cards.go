@@ -0,0 +1,69 @@
+package payriff
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// SavedCard is a card a customer saved for AutoPay
+type SavedCard struct {
+	CardUUID       CardUUID `json:"cardUuid"`
+	MaskedPan      string   `json:"maskedPan"`
+	Brand          string   `json:"brand"`
+	CardHolderName string   `json:"cardHolderName"`
+	// ExpiryDate is in MM/YY form
+	ExpiryDate  string `json:"expiryDate"`
+	CustomerID  string `json:"customerId"`
+	CreatedDate string `json:"createdDate"`
+}
+
+// Expiry parses ExpiryDate
+func (c SavedCard) Expiry() (CardExpiry, error) {
+	return ParseCardExpiry(c.ExpiryDate)
+}
+
+// Tracked returns the card for a CardExpiryMonitor
+func (c SavedCard) Tracked() (TrackedCard, error) {
+	expiry, err := c.Expiry()
+	if err != nil {
+		return TrackedCard{}, err
+	}
+	return TrackedCard{CardUUID: c.CardUUID, MaskedPan: c.MaskedPan, CustomerID: c.CustomerID, Expiry: expiry}, nil
+}
+
+// ListCards returns the cards saved by a customer
+func (s *SDK) ListCards(ctx context.Context, customerID string) (*ApiResponse[[]SavedCard], error) {
+	endpoint := "/cards?" + url.Values{"customerId": {customerID}}.Encode()
+	resp, err := s.makeRequest(ctx, endpoint, http.MethodGet, ScopeSecret, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse[[]SavedCard](s, "GET /cards", resp)
+}
+
+// GetCard retrieves a saved card by UUID
+func (s *SDK) GetCard(ctx context.Context, cardUUID CardUUID) (*ApiResponse[SavedCard], error) {
+	resp, err := s.makeRequest(ctx, fmt.Sprintf("/cards/%s", cardUUID), http.MethodGet, ScopeSecret, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse[SavedCard](s, "GET /cards/{cardUuid}", resp)
+}
+
+// DeleteCard removes a saved card so it can no longer be charged
+func (s *SDK) DeleteCard(ctx context.Context, cardUUID CardUUID) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+
+	resp, err := s.makeRequest(ctx, fmt.Sprintf("/cards/%s", cardUUID), http.MethodDelete, ScopeSecret, nil)
+	if err != nil {
+		return err
+	}
+
+	return s.checkResult(resp)
+}
@@ -0,0 +1,135 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxDescriptionLength caps descriptions generated from carts
+const maxDescriptionLength = 255
+
+// CartItem is a line item of a shopping cart
+type CartItem struct {
+	SKU       string
+	Name      string
+	Quantity  int
+	UnitPrice Amount
+}
+
+// Total returns the line total
+func (i CartItem) Total() Amount {
+	return i.UnitPrice.Mul(int64(i.Quantity))
+}
+
+// Customer identifies the buyer of an order, for receipts and invoices
+type Customer struct {
+	FullName string `json:"fullName,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Phone    string `json:"phoneNumber,omitempty"`
+}
+
+// IsZero reports whether no customer detail is set
+func (c Customer) IsZero() bool {
+	return c == Customer{}
+}
+
+// Cart is the payment-relevant view of a shopping cart
+type Cart struct {
+	// Reference is the merchant's cart or order number
+	Reference string
+	Items     []CartItem
+	// Total overrides the sum of the items, e.g. after discounts and shipping
+	Total    Amount
+	Currency Currency
+	Customer Customer
+}
+
+// CartAdapter exposes a commerce platform's cart to the SDK. Implement it
+// on top of your platform's cart or order model
+type CartAdapter interface {
+	PaymentCart(ctx context.Context) (Cart, error)
+}
+
+// CartAdapterFunc adapts a function to the CartAdapter interface
+type CartAdapterFunc func(ctx context.Context) (Cart, error)
+
+// PaymentCart calls f(ctx)
+func (f CartAdapterFunc) PaymentCart(ctx context.Context) (Cart, error) {
+	return f(ctx)
+}
+
+// PaymentCart lets a Cart value serve as its own adapter
+func (c Cart) PaymentCart(ctx context.Context) (Cart, error) {
+	return c, nil
+}
+
+// Amount returns Total, or the sum of the item totals when Total is unset
+func (c Cart) Amount() Amount {
+	if c.Total.IsPositive() {
+		return c.Total
+	}
+
+	var sum Amount
+	for _, item := range c.Items {
+		sum = sum.Add(item.Total())
+	}
+	return sum
+}
+
+// Description summarizes the cart for the order description
+func (c Cart) Description() string {
+	var b strings.Builder
+	if c.Reference != "" {
+		fmt.Fprintf(&b, "Order %s", c.Reference)
+	}
+	for i, item := range c.Items {
+		switch {
+		case i == 0 && b.Len() > 0:
+			b.WriteString(": ")
+		case i > 0:
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%dx %s", item.Quantity, item.Name)
+	}
+
+	desc := b.String()
+	if r := []rune(desc); len(r) > maxDescriptionLength {
+		desc = string(r[:maxDescriptionLength-1]) + "…"
+	}
+	return desc
+}
+
+// OrderRequest builds a CreateOrderRequest from the cart
+func (c Cart) OrderRequest() (CreateOrderRequest, error) {
+	amount := c.Amount()
+	if !amount.IsPositive() {
+		return CreateOrderRequest{}, errors.New("payriff: cart total must be positive")
+	}
+
+	req := CreateOrderRequest{
+		Amount:      amount,
+		Description: c.Description(),
+		Currency:    c.Currency,
+	}
+	if !c.Customer.IsZero() {
+		customer := c.Customer
+		req.Customer = &customer
+	}
+	return req, nil
+}
+
+// CreateOrderFromCart creates a payment order for the cart exposed by adapter
+func (s *SDK) CreateOrderFromCart(ctx context.Context, adapter CartAdapter) (*ApiResponse[OrderPayload], error) {
+	cart, err := adapter.PaymentCart(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cart: %w", err)
+	}
+
+	req, err := cart.OrderRequest()
+	if err != nil {
+		return nil, err
+	}
+	return s.CreateOrderContext(ctx, req)
+}
@@ -0,0 +1,191 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCheckoutNotFound is returned when a checkout session does not exist
+var ErrCheckoutNotFound = errors.New("payriff: checkout session not found")
+
+// Checkout creates a purchase order with the configured defaults and
+// returns where to send the shopper. Unlike CreateOrderContext, a
+// non-success result code is always returned as an *APIError
+func (s *SDK) Checkout(ctx context.Context, amount Amount, description string) (paymentURL string, orderID OrderID, err error) {
+	resp, err := s.CreateOrderContext(ctx, CreateOrderRequest{Amount: amount, Description: description})
+	if err != nil {
+		return "", "", err
+	}
+	if err := resp.Err(); err != nil {
+		return "", "", err
+	}
+	return resp.Payload.PaymentURL, resp.Payload.OrderID, nil
+}
+
+// CheckoutState tracks where a shopper is in the checkout flow
+type CheckoutState string
+
+const (
+	CheckoutCreated    CheckoutState = "created"
+	CheckoutRedirected CheckoutState = "redirected"
+	CheckoutReturned   CheckoutState = "returned"
+	CheckoutConfirmed  CheckoutState = "confirmed"
+	CheckoutFailed     CheckoutState = "failed"
+)
+
+// CheckoutSession ties a shopper session to a Payriff order
+type CheckoutSession struct {
+	ID         string
+	OrderID    OrderID
+	PaymentURL string
+	State      CheckoutState
+	// Status is the last payment status confirmed with the gateway
+	Status    Status
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	checkouts *Checkouts
+}
+
+// CheckoutStore persists checkout sessions
+type CheckoutStore interface {
+	SaveCheckout(ctx context.Context, session *CheckoutSession) error
+	// LoadCheckout returns ErrCheckoutNotFound for unknown session IDs
+	LoadCheckout(ctx context.Context, id string) (*CheckoutSession, error)
+}
+
+// Checkouts creates and loads checkout sessions backed by a store
+type Checkouts struct {
+	SDK   *SDK
+	Store CheckoutStore
+}
+
+// Start creates an order for the shopper session and persists the session
+func (c *Checkouts) Start(ctx context.Context, sessionID string, req CreateOrderRequest) (*CheckoutSession, error) {
+	resp, err := c.SDK.CreateOrderContext(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create checkout order: %w", err)
+	}
+	if err := resp.Err(); err != nil {
+		return nil, fmt.Errorf("checkout order rejected: %w", err)
+	}
+
+	now := time.Now()
+	session := &CheckoutSession{
+		ID:         sessionID,
+		OrderID:    resp.Payload.OrderID,
+		PaymentURL: resp.Payload.PaymentURL,
+		State:      CheckoutCreated,
+		Status:     StatusCreated,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		checkouts:  c,
+	}
+	if err := c.Store.SaveCheckout(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to save checkout session: %w", err)
+	}
+	return session, nil
+}
+
+// Load returns a stored checkout session
+func (c *Checkouts) Load(ctx context.Context, sessionID string) (*CheckoutSession, error) {
+	session, err := c.Store.LoadCheckout(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.checkouts = c
+	return session, nil
+}
+
+// Redirected records that the shopper was sent to the payment page
+func (cs *CheckoutSession) Redirected(ctx context.Context) error {
+	return cs.transition(ctx, CheckoutRedirected)
+}
+
+// Returned records that the shopper came back from the payment page
+func (cs *CheckoutSession) Returned(ctx context.Context) error {
+	return cs.transition(ctx, CheckoutReturned)
+}
+
+// Confirm verifies the order with GetOrderInfo and moves the session to
+// confirmed or failed once the gateway reports a final outcome. Pending
+// orders leave the state unchanged, and so do failed or stale lookups,
+// which are returned as errors
+func (cs *CheckoutSession) Confirm(ctx context.Context) (Status, error) {
+	if cs.checkouts == nil {
+		return "", errors.New("payriff: checkout session is not bound to Checkouts")
+	}
+
+	info, err := cs.checkouts.SDK.GetOrderInfoContext(ctx, cs.OrderID)
+	if err != nil {
+		return "", fmt.Errorf("failed to confirm checkout %s: %w", cs.ID, err)
+	}
+	if err := info.Err(); err != nil {
+		return "", fmt.Errorf("failed to confirm checkout %s: %w", cs.ID, err)
+	}
+	if info.Stale {
+		return "", fmt.Errorf("failed to confirm checkout %s: %w", cs.ID, ErrGatewayUnavailable)
+	}
+
+	cs.Status = info.Payload.PaymentStatus
+	switch {
+	case cs.Status.IsSuccessful():
+		return cs.Status, cs.transition(ctx, CheckoutConfirmed)
+	case cs.Status.IsFailed():
+		return cs.Status, cs.transition(ctx, CheckoutFailed)
+	default:
+		return cs.Status, cs.save(ctx)
+	}
+}
+
+func (cs *CheckoutSession) transition(ctx context.Context, state CheckoutState) error {
+	cs.State = state
+	return cs.save(ctx)
+}
+
+func (cs *CheckoutSession) save(ctx context.Context) error {
+	if cs.checkouts == nil {
+		return errors.New("payriff: checkout session is not bound to Checkouts")
+	}
+
+	cs.UpdatedAt = time.Now()
+	if err := cs.checkouts.Store.SaveCheckout(ctx, cs); err != nil {
+		return fmt.Errorf("failed to save checkout session: %w", err)
+	}
+	return nil
+}
+
+// MemoryCheckoutStore is an in-process CheckoutStore
+type MemoryCheckoutStore struct {
+	mu       sync.Mutex
+	sessions map[string]CheckoutSession
+}
+
+// SaveCheckout implements CheckoutStore
+func (m *MemoryCheckoutStore) SaveCheckout(ctx context.Context, session *CheckoutSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.sessions == nil {
+		m.sessions = make(map[string]CheckoutSession)
+	}
+	stored := *session
+	stored.checkouts = nil
+	m.sessions[session.ID] = stored
+	return nil
+}
+
+// LoadCheckout implements CheckoutStore
+func (m *MemoryCheckoutStore) LoadCheckout(ctx context.Context, id string) (*CheckoutSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCheckoutNotFound, id)
+	}
+	return &session, nil
+}
@@ -0,0 +1,19 @@
+package payriff
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Client is the order lifecycle API of the SDK, for code that should run
+// against a fake in unit tests, such as payrifftest.FakeClient
+type Client interface {
+	CreateOrderContext(ctx context.Context, req CreateOrderRequest, opts ...RequestOption) (*ApiResponse[OrderPayload], error)
+	GetOrderInfoContext(ctx context.Context, orderID OrderID, opts ...RequestOption) (*ApiResponse[OrderInfo], error)
+	RefundContext(ctx context.Context, req RefundRequest, opts ...RequestOption) (*ApiResponse[json.RawMessage], error)
+	CompleteContext(ctx context.Context, req CompleteRequest, opts ...RequestOption) (*ApiResponse[CompletePayload], error)
+	ChargeSavedCard(ctx context.Context, req AutoPayRequest, opts ...RequestOption) (*ApiResponse[AutoPayResult], error)
+	Reverse(ctx context.Context, req ReverseRequest, opts ...RequestOption) (*ApiResponse[ReversePayload], error)
+}
+
+var _ Client = (*SDK)(nil)
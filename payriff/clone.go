@@ -0,0 +1,54 @@
+package payriff
+
+import (
+	"context"
+	"fmt"
+)
+
+// CloneOrder creates a new order with the amount, description, currency,
+// operation and customer of an existing one, e.g. for a "retry payment" button
+// after a decline or an expired link. Non-zero fields of overrides replace
+// the copied values
+func (s *SDK) CloneOrder(ctx context.Context, orderID OrderID, overrides CreateOrderRequest) (*ApiResponse[OrderPayload], error) {
+	info, err := s.GetOrderInfoContext(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order %s to clone: %w", orderID, err)
+	}
+	if err := info.Err(); err != nil {
+		return nil, fmt.Errorf("order %s cannot be cloned: %w", orderID, err)
+	}
+
+	req := CreateOrderRequest{
+		Amount:      AmountOf(info.Payload.Amount),
+		Description: info.Payload.Description,
+		Operation:   info.Payload.OperationType,
+		Currency:    info.Payload.CurrencyType,
+		TerminalID:  info.Payload.TerminalID,
+		CardSave:    overrides.CardSave,
+		Language:    overrides.Language,
+		CallbackURL: overrides.CallbackURL,
+		Theme:       overrides.Theme,
+		Installment: overrides.Installment,
+		Customer:    info.Payload.Customer,
+	}
+	if !overrides.Amount.IsZero() {
+		req.Amount = overrides.Amount
+	}
+	if overrides.Description != "" {
+		req.Description = overrides.Description
+	}
+	if overrides.Operation != "" {
+		req.Operation = overrides.Operation
+	}
+	if overrides.Currency != "" {
+		req.Currency = overrides.Currency
+	}
+	if overrides.TerminalID != "" {
+		req.TerminalID = overrides.TerminalID
+	}
+	if overrides.Customer != nil {
+		req.Customer = overrides.Customer
+	}
+
+	return s.CreateOrderContext(ctx, req)
+}
@@ -0,0 +1,150 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// RateRule is a contracted commission rate, in the unit of
+// OrderInfo.CommissionRate. Empty fields match any order
+type RateRule struct {
+	Currency   Currency
+	Operation  Operation
+	TerminalID string
+	Rate       float64
+}
+
+// specificity counts the fields the rule constrains
+func (r RateRule) specificity() int {
+	n := 0
+	for _, set := range []bool{r.Currency != "", r.Operation != "", r.TerminalID != ""} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+func (r RateRule) matches(o OrderInfo) bool {
+	return (r.Currency == "" || r.Currency == o.CurrencyType) &&
+		(r.Operation == "" || r.Operation == o.OperationType) &&
+		(r.TerminalID == "" || r.TerminalID == o.TerminalID)
+}
+
+// RateTable is a merchant's contracted commission rates
+type RateTable []RateRule
+
+// Lookup returns the rate of the most specific rule matching o. Among
+// equally specific rules the first one wins
+func (t RateTable) Lookup(o OrderInfo) (float64, bool) {
+	best := -1
+	for i, r := range t {
+		if r.matches(o) && (best < 0 || r.specificity() > t[best].specificity()) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return 0, false
+	}
+	return t[best].Rate, true
+}
+
+// CommissionDiscrepancy is an order charged a commission rate other than
+// the contracted one
+type CommissionDiscrepancy struct {
+	OrderID  OrderID
+	Currency Currency
+	Amount   Amount
+	Expected float64
+	Charged  float64
+	// Overcharge is the commission charged beyond the contracted rate,
+	// negative when the order was undercharged
+	Overcharge Amount
+}
+
+func (d CommissionDiscrepancy) String() string {
+	return fmt.Sprintf("order %s: charged %g%%, contracted %g%% (%s %s)",
+		d.OrderID, d.Charged, d.Expected, d.Overcharge, d.Currency)
+}
+
+// CommissionAuditor compares the commission rate the gateway reports on
+// each order with the contracted rate table, catching billing errors
+type CommissionAuditor struct {
+	Rates RateTable
+	// Tolerance is the difference in rate ignored as rounding, defaults
+	// to 0.0001
+	Tolerance float64
+	// Notifier receives NotificationCommissionDispute from Audit
+	Notifier Notifier
+}
+
+// Check compares one order against the rate table. Orders without a
+// reported rate, without a matching rule or that were never paid are skipped
+func (a *CommissionAuditor) Check(o OrderInfo) (CommissionDiscrepancy, bool) {
+	if o.CommissionRate == nil || !o.PaymentStatus.IsPaid() {
+		return CommissionDiscrepancy{}, false
+	}
+	expected, ok := a.Rates.Lookup(o)
+	if !ok {
+		return CommissionDiscrepancy{}, false
+	}
+
+	tolerance := a.Tolerance
+	if tolerance <= 0 {
+		tolerance = 0.0001
+	}
+	charged := *o.CommissionRate
+	if math.Abs(charged-expected) <= tolerance {
+		return CommissionDiscrepancy{}, false
+	}
+
+	return CommissionDiscrepancy{
+		OrderID:    o.OrderID,
+		Currency:   o.CurrencyType,
+		Amount:     AmountOf(o.Amount),
+		Expected:   expected,
+		Charged:    charged,
+		Overcharge: AmountOf(o.Amount * (charged - expected) / 100),
+	}, true
+}
+
+// Discrepancies checks every order
+func (a *CommissionAuditor) Discrepancies(orders []OrderInfo) []CommissionDiscrepancy {
+	var found []CommissionDiscrepancy
+	for _, o := range orders {
+		if d, ok := a.Check(o); ok {
+			found = append(found, d)
+		}
+	}
+	return found
+}
+
+// Audit checks orders and sends one NotificationCommissionDispute listing
+// the discrepancies, if any
+func (a *CommissionAuditor) Audit(ctx context.Context, orders []OrderInfo) ([]CommissionDiscrepancy, error) {
+	found := a.Discrepancies(orders)
+	if len(found) == 0 {
+		return nil, nil
+	}
+	if a.Notifier == nil {
+		return found, errors.New("payriff: commission auditor has no notifier")
+	}
+
+	lines := make([]string, len(found))
+	for i, d := range found {
+		lines[i] = d.String()
+	}
+	n := Notification{
+		Kind:    NotificationCommissionDispute,
+		Subject: fmt.Sprintf("%d orders charged off-contract commission", len(found)),
+		Body:    strings.Join(lines, "\n"),
+		Data:    map[string]any{"discrepancies": found},
+	}
+	if err := safeCall(func() error { return a.Notifier.Notify(ctx, n) }); err != nil {
+		return found, fmt.Errorf("failed to notify about commission discrepancies: %w", err)
+	}
+	return found, nil
+}
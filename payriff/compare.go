@@ -0,0 +1,122 @@
+package payriff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultLegacyVersion is the API version MigrationComparer compares against
+const DefaultLegacyVersion = "v2"
+
+// OrderComparison is the field-level diff of one order between the legacy
+// and current API. In each FieldDiff, A is the legacy value and B the current one
+type OrderComparison struct {
+	OrderID OrderID
+	Diffs   []FieldDiff
+}
+
+// Equal reports whether the two APIs agree on every compared field
+func (c *OrderComparison) Equal() bool {
+	return len(c.Diffs) == 0
+}
+
+// MigrationComparer compares orders between a legacy API version and the
+// version the SDK is configured for, to validate an upgrade before cutting
+// over. The legacy order is fetched by a copy of SDK switched to
+// LegacyVersion with WithAPIVersion, so both calls share credentials,
+// retries and connections
+type MigrationComparer struct {
+	SDK *SDK
+	// LegacyVersion defaults to DefaultLegacyVersion
+	LegacyVersion string
+	// LegacyPath is the legacy order endpoint, with {orderId} replaced by
+	// the order ID. Defaults to /orders/{orderId}
+	LegacyPath string
+	// FieldMap maps dotted legacy field paths to OrderInfo JSON paths, e.g.
+	// "orderstatus" to "paymentStatus". When set, only mapped fields are
+	// compared, keyed by their current path
+	FieldMap map[string]string
+	// Ignore lists paths left out of the comparison
+	Ignore []string
+}
+
+// CompareOrder fetches orderID from both APIs and diffs the results. Failed
+// or stale lookups are returned as errors rather than compared
+func (c *MigrationComparer) CompareOrder(ctx context.Context, orderID OrderID) (*OrderComparison, error) {
+	raw, err := c.fetchLegacy(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch legacy order %s: %w", orderID, err)
+	}
+	var legacy any
+	if err := json.Unmarshal(raw, &legacy); err != nil {
+		return nil, fmt.Errorf("failed to decode legacy order %s: %w", orderID, err)
+	}
+
+	info, err := c.SDK.GetOrderInfoContext(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch order %s: %w", orderID, err)
+	}
+	if err := info.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch order %s: %w", orderID, err)
+	}
+	// A cached order may predate the legacy one, which would show as drift
+	if info.Stale {
+		return nil, fmt.Errorf("failed to fetch order %s: %w", orderID, ErrGatewayUnavailable)
+	}
+	current, err := toJSONValue(info.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.FieldMap != nil {
+		mappedLegacy := make(map[string]any, len(c.FieldMap))
+		mappedCurrent := make(map[string]any, len(c.FieldMap))
+		for from, to := range c.FieldMap {
+			mappedLegacy[to] = lookupPath(legacy, from)
+			mappedCurrent[to] = lookupPath(current, to)
+		}
+		legacy, current = mappedLegacy, mappedCurrent
+	}
+
+	ignore := make(map[string]bool, len(c.Ignore))
+	for _, path := range c.Ignore {
+		ignore[path] = true
+	}
+	return &OrderComparison{OrderID: orderID, Diffs: diffJSON("", legacy, current, ignore)}, nil
+}
+
+// fetchLegacy returns the raw payload of orderID from the legacy version
+func (c *MigrationComparer) fetchLegacy(ctx context.Context, orderID OrderID) (json.RawMessage, error) {
+	version, path := c.LegacyVersion, c.LegacyPath
+	if version == "" {
+		version = DefaultLegacyVersion
+	}
+	if path == "" {
+		path = "/orders/{orderId}"
+	}
+
+	legacy := c.SDK.WithAPIVersion(version)
+	resp, err := legacy.makeRequest(ctx, strings.ReplaceAll(path, "{orderId}", string(orderID)), http.MethodGet, ScopePublic, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccessful() {
+		return nil, newAPIError(resp)
+	}
+	return resp.Payload, nil
+}
+
+// lookupPath returns the value at a dotted path of object keys, or nil
+func lookupPath(v any, path string) any {
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
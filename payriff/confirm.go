@@ -0,0 +1,66 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrCallbackMismatch is returned when a callback disagrees with the
+// gateway's authoritative order status
+var ErrCallbackMismatch = errors.New("payriff: callback does not match gateway order status")
+
+// ConfirmCallbacks returns a DeliveryVerifier that fetches every callback's
+// order with GetOrderInfo and rejects the delivery unless the gateway
+// reports the same status, protecting against spoofed or stale callbacks.
+// Lookups that fail in transport, time out or hit a 5xx or maintenance
+// response wrap ErrVerificationUnavailable, so the callback is retried;
+// lookups the gateway answers with a failure, such as an unknown order,
+// reject the callback
+func (s *SDK) ConfirmCallbacks() DeliveryVerifier {
+	return DeliveryVerifierFunc(func(ctx context.Context, d *Delivery) error {
+		event, err := d.ParseCallback()
+		if err != nil {
+			return err
+		}
+
+		info, err := s.GetOrderInfoContext(ctx, event.OrderID)
+		if err != nil {
+			if lookupUnavailable(err) {
+				return fmt.Errorf("%w: failed to confirm order %s: %w", ErrVerificationUnavailable, event.OrderID, err)
+			}
+			return fmt.Errorf("failed to confirm order %s: %w", event.OrderID, err)
+		}
+		if info.Stale {
+			return fmt.Errorf("%w: failed to confirm order %s: %w", ErrVerificationUnavailable, event.OrderID, ErrGatewayUnavailable)
+		}
+		if err := info.Err(); err != nil {
+			return fmt.Errorf("failed to confirm order %s: %w", event.OrderID, err)
+		}
+		if info.Payload.PaymentStatus != event.PaymentStatus {
+			return fmt.Errorf("%w: order %s is %s, callback says %s",
+				ErrCallbackMismatch, event.OrderID, info.Payload.PaymentStatus, event.PaymentStatus)
+		}
+		return nil
+	})
+}
+
+// lookupUnavailable reports whether a failed lookup says nothing about the
+// order, so the callback should be retried rather than rejected. Failure
+// responses, also as errors with Config.ErrorOnFailure, and 4xx statuses
+// are definitive
+func lookupUnavailable(err error) bool {
+	if errors.Is(err, ErrGatewayMaintenance) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrDeadLetterNotFound is returned when a dead letter does not exist
+var ErrDeadLetterNotFound = errors.New("payriff: dead letter not found")
+
+// DeadLetter is a delivery whose handler kept failing
+type DeadLetter struct {
+	Key       string
+	Delivery  Delivery
+	Attempts  int
+	LastError string
+	ParkedAt  time.Time
+}
+
+// DeadLetterQueue stores deliveries parked after repeated handler failures
+type DeadLetterQueue interface {
+	Park(ctx context.Context, dl DeadLetter) error
+	Get(ctx context.Context, key string) (DeadLetter, error)
+	List(ctx context.Context) ([]DeadLetter, error)
+	Remove(ctx context.Context, key string) error
+}
+
+// Replay runs the handler for a parked delivery again and removes it from
+// the dead-letter queue on success
+func (p *Processor) Replay(ctx context.Context, key string) error {
+	if p.DeadLetters == nil {
+		return errors.New("payriff: processor has no dead-letter queue")
+	}
+
+	dl, err := p.DeadLetters.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := p.safeCall(func() error { return p.Handler(ctx, &dl.Delivery) }); err != nil {
+		return fmt.Errorf("failed to replay delivery %s: %w", key, err)
+	}
+	return p.DeadLetters.Remove(ctx, key)
+}
+
+// ReplayAll replays every parked delivery, returning the joined failures
+func (p *Processor) ReplayAll(ctx context.Context) error {
+	if p.DeadLetters == nil {
+		return errors.New("payriff: processor has no dead-letter queue")
+	}
+
+	letters, err := p.DeadLetters.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, dl := range letters {
+		if err := p.Replay(ctx, dl.Key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// park moves a failing delivery to the dead-letter queue and commits it so
+// gateway redeliveries are acknowledged
+func (p *Processor) park(ctx context.Context, key string, d *Delivery, attempts int, cause error) error {
+	err := p.DeadLetters.Park(ctx, DeadLetter{
+		Key:       key,
+		Delivery:  *d,
+		Attempts:  attempts,
+		LastError: cause.Error(),
+		ParkedAt:  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to park delivery %s: %w", key, err)
+	}
+	if err := p.Store.Commit(ctx, key); err != nil {
+		return fmt.Errorf("failed to commit parked delivery %s: %w", key, err)
+	}
+	return nil
+}
+
+// MemoryDeadLetterQueue is an in-process DeadLetterQueue
+type MemoryDeadLetterQueue struct {
+	mu      sync.Mutex
+	letters map[string]DeadLetter
+}
+
+// Park implements DeadLetterQueue
+func (q *MemoryDeadLetterQueue) Park(ctx context.Context, dl DeadLetter) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.letters == nil {
+		q.letters = make(map[string]DeadLetter)
+	}
+	q.letters[dl.Key] = dl
+	return nil
+}
+
+// Get implements DeadLetterQueue
+func (q *MemoryDeadLetterQueue) Get(ctx context.Context, key string) (DeadLetter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	dl, ok := q.letters[key]
+	if !ok {
+		return DeadLetter{}, fmt.Errorf("%w: %s", ErrDeadLetterNotFound, key)
+	}
+	return dl, nil
+}
+
+// List implements DeadLetterQueue, oldest first
+func (q *MemoryDeadLetterQueue) List(ctx context.Context) ([]DeadLetter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	letters := make([]DeadLetter, 0, len(q.letters))
+	for _, dl := range q.letters {
+		letters = append(letters, dl)
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].ParkedAt.Before(letters[j].ParkedAt)
+	})
+	return letters, nil
+}
+
+// Remove implements DeadLetterQueue
+func (q *MemoryDeadLetterQueue) Remove(ctx context.Context, key string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.letters, key)
+	return nil
+}
@@ -0,0 +1,131 @@
+package payriff
+
+// DeclineAction is what a shopper or merchant should do after a decline
+type DeclineAction string
+
+const (
+	// DeclineActionRetry means the same card may succeed later
+	DeclineActionRetry DeclineAction = "retry_later"
+	// DeclineActionOtherCard means the shopper should pay with another card
+	DeclineActionOtherCard DeclineAction = "use_another_card"
+	// DeclineActionCheckDetails means the card details were entered incorrectly
+	DeclineActionCheckDetails DeclineAction = "check_card_details"
+	// DeclineActionContactIssuer means the shopper should call their bank
+	DeclineActionContactIssuer DeclineAction = "contact_issuer"
+)
+
+// DeclineClass tells whether and when a declined charge may be retried
+type DeclineClass string
+
+const (
+	// DeclineHard declines never succeed on retry with the same card
+	DeclineHard DeclineClass = "hard"
+	// DeclineSoftLater declines may succeed when retried later, e.g. after
+	// the customer's balance is topped up
+	DeclineSoftLater DeclineClass = "soft_later"
+	// DeclineSoftAuthentication declines may succeed when retried with
+	// shopper authentication (3-D Secure), so not through AutoPay
+	DeclineSoftAuthentication DeclineClass = "soft_authentication"
+)
+
+// DeclineInfo explains why a payment was declined
+type DeclineInfo struct {
+	// Code is the issuer (ISO 8583) response code, empty when unknown
+	Code string
+	// Reason is a human-readable reason suitable for shoppers
+	Reason string
+	Action DeclineAction
+	Class  DeclineClass
+}
+
+// Retriable reports whether the charge may be retried unattended, e.g. by
+// subscription dunning through AutoPay
+func (d DeclineInfo) Retriable() bool {
+	return d.Class == DeclineSoftLater
+}
+
+// declineCodes maps ISO 8583 response codes to decline details
+var declineCodes = map[string]DeclineInfo{
+	"01": {Reason: "refer to card issuer", Action: DeclineActionContactIssuer, Class: DeclineSoftLater},
+	"03": {Reason: "invalid merchant", Action: DeclineActionOtherCard, Class: DeclineHard},
+	"04": {Reason: "card blocked", Action: DeclineActionOtherCard, Class: DeclineHard},
+	"05": {Reason: "do not honor", Action: DeclineActionContactIssuer, Class: DeclineSoftLater},
+	"12": {Reason: "invalid transaction", Action: DeclineActionOtherCard, Class: DeclineHard},
+	"13": {Reason: "invalid amount", Action: DeclineActionCheckDetails, Class: DeclineHard},
+	"14": {Reason: "invalid card number", Action: DeclineActionCheckDetails, Class: DeclineHard},
+	"15": {Reason: "no such issuer", Action: DeclineActionCheckDetails, Class: DeclineHard},
+	"19": {Reason: "re-enter transaction", Action: DeclineActionRetry, Class: DeclineSoftLater},
+	"1A": {Reason: "additional authentication required", Action: DeclineActionRetry, Class: DeclineSoftAuthentication},
+	"30": {Reason: "format error", Action: DeclineActionCheckDetails, Class: DeclineHard},
+	"41": {Reason: "card reported lost", Action: DeclineActionOtherCard, Class: DeclineHard},
+	"43": {Reason: "card reported stolen", Action: DeclineActionOtherCard, Class: DeclineHard},
+	"51": {Reason: "insufficient funds", Action: DeclineActionOtherCard, Class: DeclineSoftLater},
+	"54": {Reason: "card expired", Action: DeclineActionOtherCard, Class: DeclineHard},
+	"55": {Reason: "incorrect PIN", Action: DeclineActionCheckDetails, Class: DeclineSoftAuthentication},
+	"57": {Reason: "transaction not permitted for card", Action: DeclineActionContactIssuer, Class: DeclineHard},
+	"58": {Reason: "transaction not permitted for terminal", Action: DeclineActionOtherCard, Class: DeclineHard},
+	"59": {Reason: "suspected fraud", Action: DeclineActionContactIssuer, Class: DeclineHard},
+	"61": {Reason: "amount limit exceeded", Action: DeclineActionContactIssuer, Class: DeclineSoftLater},
+	"62": {Reason: "card restricted", Action: DeclineActionContactIssuer, Class: DeclineHard},
+	"65": {Reason: "transaction count limit exceeded", Action: DeclineActionContactIssuer, Class: DeclineSoftAuthentication},
+	"75": {Reason: "PIN tries exceeded", Action: DeclineActionContactIssuer, Class: DeclineHard},
+	"82": {Reason: "incorrect CVV", Action: DeclineActionCheckDetails, Class: DeclineHard},
+	"91": {Reason: "issuer unavailable", Action: DeclineActionRetry, Class: DeclineSoftLater},
+	"96": {Reason: "system malfunction", Action: DeclineActionRetry, Class: DeclineSoftLater},
+}
+
+// LookupDecline returns the decline details for an issuer response code.
+// Unknown codes are reported as a generic hard decline
+func LookupDecline(code string) DeclineInfo {
+	info, ok := declineCodes[code]
+	if !ok {
+		info = DeclineInfo{Reason: "declined by issuer", Action: DeclineActionContactIssuer, Class: DeclineHard}
+	}
+	info.Code = code
+	return info
+}
+
+// Decline returns the decline details of a declined transaction, or nil
+func (t Transaction) Decline() *DeclineInfo {
+	if t.Status != StatusDeclined {
+		return nil
+	}
+	info := LookupDecline(t.ResponseCode)
+	return &info
+}
+
+// Decline returns the decline details of the order's latest declined
+// transaction, or nil when the order was not declined
+func (o OrderInfo) Decline() *DeclineInfo {
+	if o.PaymentStatus != StatusDeclined {
+		return nil
+	}
+	for i := len(o.Transactions) - 1; i >= 0; i-- {
+		if d := o.Transactions[i].Decline(); d != nil {
+			return d
+		}
+	}
+	info := LookupDecline("")
+	return &info
+}
+
+// Decline returns the decline details of a charge that was not approved,
+// or nil
+func (r AutoPayResult) Decline() *DeclineInfo {
+	if r.Approved() {
+		return nil
+	}
+	if r.ResponseCode != "" {
+		info := LookupDecline(r.ResponseCode)
+		return &info
+	}
+	if t := r.Transaction(); t != nil && t.ResponseCode != "" {
+		info := LookupDecline(t.ResponseCode)
+		return &info
+	}
+	if r.PaymentStatus != StatusDeclined {
+		return nil
+	}
+	info := LookupDecline("")
+	return &info
+}
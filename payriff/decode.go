@@ -0,0 +1,141 @@
+package payriff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DriftReport lists payload fields that differ from the SDK's types for
+// one endpoint
+type DriftReport struct {
+	// Endpoint is the endpoint pattern, e.g. GET /orders/{orderId}
+	Endpoint string
+	// Unknown fields are in the payload but not in the SDK type
+	Unknown []string
+	// Missing fields are in the SDK type but not in the payload
+	Missing []string
+	// Occurrences counts the responses with drift seen for Endpoint
+	Occurrences int
+}
+
+// driftCounts counts drifting responses per endpoint
+type driftCounts struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// DriftCounts returns the number of drifting responses seen per endpoint
+// since the SDK was created. Drift is only detected with Config.DetectDrift
+func (s *SDK) DriftCounts() map[string]int {
+	out := make(map[string]int)
+	if s.drift == nil {
+		return out
+	}
+
+	s.drift.mu.Lock()
+	defer s.drift.mu.Unlock()
+	for endpoint, n := range s.drift.counts {
+		out[endpoint] = n
+	}
+	return out
+}
+
+// decodeResponse decodes the payload of resp into an ApiResponse and copies
+// the response metadata. endpoint names the endpoint pattern for drift
+// reports. Non-success responses become errors with Config.ErrorOnFailure
+func decodeResponse[T any](s *SDK, endpoint string, resp *Response) (*ApiResponse[T], error) {
+	if err := s.checkResult(resp); err != nil {
+		return nil, err
+	}
+
+	var result ApiResponse[T]
+	if len(resp.Payload) > 0 {
+		if err := json.Unmarshal(resp.Payload, &result.Payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s payload: %w", endpoint, err)
+		}
+		if s.drift != nil {
+			s.detectDrift(endpoint, resp.Payload, result.Payload)
+		}
+	}
+
+	// Copy response metadata
+	result.Code = resp.Code
+	result.Message = resp.Message
+	result.Route = resp.Route
+	result.InternalMessage = resp.InternalMessage
+	result.ResponseID = resp.ResponseID
+	result.TestMode = s.environment.IsTest()
+
+	return &result, nil
+}
+
+// detectDrift compares the fields of a raw payload with those of the value
+// it was decoded into
+func (s *SDK) detectDrift(endpoint string, raw json.RawMessage, decoded any) {
+	var rawValue any
+	if err := json.Unmarshal(raw, &rawValue); err != nil {
+		return
+	}
+	decodedValue, err := toJSONValue(decoded)
+	if err != nil {
+		return
+	}
+
+	rawFields := make(map[string]bool)
+	collectFields("", rawValue, rawFields)
+	typeFields := make(map[string]bool)
+	collectFields("", decodedValue, typeFields)
+
+	report := DriftReport{Endpoint: endpoint}
+	for f := range rawFields {
+		if !typeFields[f] {
+			report.Unknown = append(report.Unknown, f)
+		}
+	}
+	for f := range typeFields {
+		if !rawFields[f] {
+			report.Missing = append(report.Missing, f)
+		}
+	}
+	if len(report.Unknown) == 0 && len(report.Missing) == 0 {
+		return
+	}
+	sort.Strings(report.Unknown)
+	sort.Strings(report.Missing)
+
+	s.drift.mu.Lock()
+	if s.drift.counts == nil {
+		s.drift.counts = make(map[string]int)
+	}
+	s.drift.counts[endpoint]++
+	report.Occurrences = s.drift.counts[endpoint]
+	s.drift.mu.Unlock()
+
+	if s.hooks.OnDrift != nil {
+		s.runHook(func() { s.hooks.OnDrift(report) })
+	}
+}
+
+// collectFields adds the paths of all object fields in v to fields. Paths
+// are lowercased, like encoding/json matches keys, and array elements
+// share a path ending in []
+func collectFields(path string, v any, fields map[string]bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			p := strings.ToLower(k)
+			if path != "" {
+				p = path + "." + p
+			}
+			fields[p] = true
+			collectFields(p, child, fields)
+		}
+	case []any:
+		for _, child := range v {
+			collectFields(path+"[]", child, fields)
+		}
+	}
+}
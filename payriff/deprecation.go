@@ -0,0 +1,46 @@
+package payriff
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// Deprecation describes the use of a deprecated SDK API
+type Deprecation struct {
+	// API is the deprecated method or field, e.g. SDK.CreateOrder
+	API string
+	// Replacement is what to use instead
+	Replacement string
+}
+
+func (d Deprecation) String() string {
+	return "payriff: " + d.API + " is deprecated, use " + d.Replacement + " instead"
+}
+
+// deprecations remembers which notices were already emitted
+type deprecations struct {
+	seen sync.Map
+}
+
+// deprecated emits a notice the first time a deprecated API is used,
+// through Hooks.OnDeprecation or as a warning on Config.Logger, falling
+// back to the default slog logger
+func (s *SDK) deprecated(api, replacement string) {
+	if s.deprecations == nil {
+		return
+	}
+	if _, loaded := s.deprecations.seen.LoadOrStore(api, struct{}{}); loaded {
+		return
+	}
+
+	d := Deprecation{API: api, Replacement: replacement}
+	if s.hooks.OnDeprecation != nil {
+		s.runHook(func() { s.hooks.OnDeprecation(d) })
+		return
+	}
+	logger := s.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Warn(d.String(), slog.String("api", d.API), slog.String("replacement", d.Replacement))
+}
@@ -0,0 +1,28 @@
+// Package payriff is an unofficial client for the Payriff payment gateway.
+//
+// # Errors
+//
+// Every error returned by the SDK wraps its cause with %w, so the standard
+// errors package can inspect the whole chain:
+//
+//   - Transport failures wrap the *url.Error returned by the HTTP client,
+//     which in turn wraps the underlying net.Error. Use errors.As with
+//     net.Error to detect timeouts.
+//   - Encoding and decoding failures wrap the encoding/json error.
+//   - Retried requests return the last real failure. When retrying stops
+//     because the context is done, the context error is wrapped as well,
+//     so both errors.Is(err, context.DeadlineExceeded) and errors.As on the
+//     gateway or network error succeed.
+//   - SDK conditions are reported with sentinel errors such as
+//     ErrGatewayUnavailable or ErrDeliveryRejected, matched with errors.Is.
+//   - With Config.ErrorOnFailure, non-success result codes are returned as
+//     *APIError, which matches ErrUnauthorized, ErrInvalidToken and
+//     ErrInvalidParameters with errors.Is.
+//
+// # Panics
+//
+// Hooks, verifiers, notifiers and webhook handlers supplied by callers run
+// with panic recovery. A panic becomes a *PanicError carrying the panic
+// value and stack; it is returned where an error can be returned and is
+// otherwise reported through Hooks.OnError or Processor.OnError.
+package payriff
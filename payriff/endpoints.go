@@ -0,0 +1,143 @@
+package payriff
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// RetryClass tells when a failed call to an endpoint may be retried
+type RetryClass string
+
+const (
+	// RetrySafe endpoints are retried whenever the retry policy allows
+	RetrySafe RetryClass = "safe"
+	// RetryWithKey endpoints are only retried when the call carries an
+	// idempotency key
+	RetryWithKey RetryClass = "idempotency_key"
+	// RetryNever endpoints are never retried
+	RetryNever RetryClass = "never"
+)
+
+// EndpointInfo describes a gateway endpoint called by the SDK
+type EndpointInfo struct {
+	// Name is the SDK method calling the endpoint
+	Name   string
+	Method string
+	// Path is the path pattern, e.g. /orders/{orderId}
+	Path  string
+	Scope KeyScope
+	// Request is the body type, nil when the endpoint takes no body
+	Request reflect.Type
+	// Response is the payload type, nil when the endpoint returns none
+	Response reflect.Type
+	// Idempotent is set when repeating a call has no further effect
+	Idempotent bool
+	// MovesMoney is set when a call charges, refunds or transfers funds
+	MovesMoney bool
+	Retry      RetryClass
+	// Feature is the merchant feature the endpoint needs, if any
+	Feature Feature
+}
+
+// Pattern returns the method and path, e.g. GET /orders/{orderId}
+func (e EndpointInfo) Pattern() string {
+	return e.Method + " " + e.Path
+}
+
+// Match reports whether a request with method and path addresses the
+// endpoint. A query string in path is ignored
+func (e EndpointInfo) Match(method, path string) bool {
+	if method != e.Method {
+		return false
+	}
+	path, _, _ = strings.Cut(path, "?")
+
+	want := strings.Split(strings.Trim(e.Path, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i, segment := range want {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if got[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// endpointsMu guards endpoints against RegisterEndpoint
+var endpointsMu sync.RWMutex
+
+// endpoints lists the endpoints of the gateway API
+var endpoints = []EndpointInfo{
+	{Name: "CreateOrderContext", Method: http.MethodPost, Path: "/orders", Scope: ScopeSecret,
+		Request: reflect.TypeFor[CreateOrderRequest](), Response: reflect.TypeFor[OrderPayload](), Retry: RetryWithKey},
+	{Name: "GetOrderInfoContext", Method: http.MethodGet, Path: "/orders/{orderId}", Scope: ScopePublic,
+		Response: reflect.TypeFor[OrderInfo](), Idempotent: true, Retry: RetrySafe},
+	{Name: "RefundContext", Method: http.MethodPost, Path: "/refund", Scope: ScopeSecret,
+		Request: reflect.TypeFor[RefundRequest](), Response: reflect.TypeFor[json.RawMessage](), MovesMoney: true, Retry: RetryWithKey, Feature: FeatureRefunds},
+	{Name: "CompleteContext", Method: http.MethodPost, Path: "/complete", Scope: ScopeSecret,
+		Request: reflect.TypeFor[CompleteRequest](), Response: reflect.TypeFor[CompletePayload](), MovesMoney: true, Retry: RetryWithKey, Feature: FeaturePreAuth},
+	{Name: "ChargeSavedCard", Method: http.MethodPost, Path: "/autoPay", Scope: ScopeSecret,
+		Request: reflect.TypeFor[AutoPayRequest](), Response: reflect.TypeFor[AutoPayResult](), MovesMoney: true, Retry: RetryWithKey, Feature: FeatureAutoPay},
+	{Name: "Reverse", Method: http.MethodPost, Path: "/reverse", Scope: ScopeSecret,
+		Request: reflect.TypeFor[ReverseRequest](), Response: reflect.TypeFor[ReversePayload](), MovesMoney: true, Retry: RetryWithKey, Feature: FeaturePreAuth},
+	{Name: "Topup", Method: http.MethodPost, Path: "/topup", Scope: ScopeSecret,
+		Request: reflect.TypeFor[TopupRequest](), Response: reflect.TypeFor[TopupPayload](), MovesMoney: true, Retry: RetryWithKey, Feature: FeatureTopup},
+	{Name: "TopupMPAY", Method: http.MethodPost, Path: "/mpay/topup", Scope: ScopeSecret,
+		Request: reflect.TypeFor[MPAYTopupRequest](), Response: reflect.TypeFor[MPAYTopupPayload](), MovesMoney: true, Retry: RetryWithKey, Feature: FeatureMPAY},
+	{Name: "CreateInvoice", Method: http.MethodPost, Path: "/invoices", Scope: ScopeSecret,
+		Request: reflect.TypeFor[CreateInvoiceRequest](), Response: reflect.TypeFor[InvoicePayload](), Retry: RetryWithKey, Feature: FeatureInvoices},
+	{Name: "GetInvoice", Method: http.MethodGet, Path: "/invoices/{invoiceUuid}", Scope: ScopePublic,
+		Response: reflect.TypeFor[InvoicePayload](), Idempotent: true, Retry: RetrySafe, Feature: FeatureInvoices},
+	{Name: "ListCards", Method: http.MethodGet, Path: "/cards", Scope: ScopeSecret,
+		Response: reflect.TypeFor[[]SavedCard](), Idempotent: true, Retry: RetrySafe, Feature: FeatureCardSave},
+	{Name: "GetCard", Method: http.MethodGet, Path: "/cards/{cardUuid}", Scope: ScopeSecret,
+		Response: reflect.TypeFor[SavedCard](), Idempotent: true, Retry: RetrySafe, Feature: FeatureCardSave},
+	{Name: "DeleteCard", Method: http.MethodDelete, Path: "/cards/{cardUuid}", Scope: ScopeSecret,
+		Idempotent: true, Retry: RetrySafe, Feature: FeatureCardSave},
+}
+
+// Endpoints returns the endpoints the SDK calls, e.g. for proxies and
+// gateways built over the SDK
+func Endpoints() []EndpointInfo {
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+
+	return append([]EndpointInfo(nil), endpoints...)
+}
+
+// LookupEndpoint returns the endpoint addressed by method and path, which
+// may be a concrete path such as /orders/ORD-1?lang=en
+func LookupEndpoint(method, path string) (EndpointInfo, bool) {
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+
+	for _, e := range endpoints {
+		if e.Match(method, path) {
+			return e, true
+		}
+	}
+	return EndpointInfo{}, false
+}
+
+// retryClass returns the retry class of a call, treating unknown GET
+// endpoints as safe and other unknown endpoints as requiring a key
+func retryClass(method, path string) RetryClass {
+	if e, ok := LookupEndpoint(method, path); ok {
+		return e.Retry
+	}
+	if method == http.MethodGet {
+		return RetrySafe
+	}
+	return RetryWithKey
+}
@@ -0,0 +1,100 @@
+package payriff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EnvelopeProfile maps the fields of a response envelope to their JSON
+// locations. Each field lists dotted paths that are tried in order, so a
+// renamed or nested field can be accepted alongside the current one
+type EnvelopeProfile struct {
+	Code            []string
+	Message         []string
+	Route           []string
+	InternalMessage []string
+	ResponseID      []string
+	Payload         []string
+}
+
+// DefaultEnvelope is the envelope of the current gateway API, also
+// accepting a message renamed to msg and a payload nested under data
+var DefaultEnvelope = EnvelopeProfile{
+	Code:            []string{"code"},
+	Message:         []string{"message", "msg"},
+	Route:           []string{"route"},
+	InternalMessage: []string{"internalMessage"},
+	ResponseID:      []string{"responseId"},
+	Payload:         []string{"payload", "data.payload", "data"},
+}
+
+// EnvelopeProfiles holds the envelope of each API version. Versions without
+// a profile use DefaultEnvelope
+var EnvelopeProfiles = map[string]EnvelopeProfile{
+	"v3": DefaultEnvelope,
+}
+
+// envelope returns the profile for the SDK's API version
+func (s *SDK) envelope() EnvelopeProfile {
+	if s.envelopeProfile != nil {
+		return *s.envelopeProfile
+	}
+	if p, ok := EnvelopeProfiles[s.apiVersion]; ok {
+		return p
+	}
+	return DefaultEnvelope
+}
+
+// decodeEnvelope decodes a response body using profile
+func decodeEnvelope(data []byte, profile EnvelopeProfile) (*Response, error) {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	var resp Response
+	fields := []struct {
+		paths []string
+		dst   any
+	}{
+		{profile.Code, &resp.Code},
+		{profile.Message, &resp.Message},
+		{profile.Route, &resp.Route},
+		{profile.InternalMessage, &resp.InternalMessage},
+		{profile.ResponseID, &resp.ResponseID},
+	}
+	for _, f := range fields {
+		raw, path := findRaw(root, f.paths)
+		if raw == nil {
+			continue
+		}
+		if err := json.Unmarshal(raw, f.dst); err != nil {
+			return nil, fmt.Errorf("envelope field %s: %w", path, err)
+		}
+	}
+	resp.Payload, _ = findRaw(root, profile.Payload)
+	return &resp, nil
+}
+
+// findRaw returns the value at the first of paths present in root
+func findRaw(root map[string]json.RawMessage, paths []string) (json.RawMessage, string) {
+	for _, path := range paths {
+		obj := root
+		keys := strings.Split(path, ".")
+		for i, key := range keys {
+			raw, ok := obj[key]
+			if !ok || string(raw) == "null" {
+				break
+			}
+			if i == len(keys)-1 {
+				return raw, path
+			}
+			obj = nil
+			if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+				break
+			}
+		}
+	}
+	return nil, ""
+}
@@ -0,0 +1,71 @@
+package payriff
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Environment selects the gateway the SDK talks to
+type Environment string
+
+const (
+	// EnvironmentProduction processes real payments
+	EnvironmentProduction Environment = "production"
+	// EnvironmentSandbox moves no money. It needs Config.BaseURL set to the
+	// sandbox host issued with the sandbox credentials
+	EnvironmentSandbox Environment = "sandbox"
+)
+
+// productionAPI is the API root of production, without the version segment
+const productionAPI = "https://api.payriff.com/api"
+
+// BaseURL returns the base URL of the environment for DefaultAPIVersion.
+// The sandbox host is issued with the sandbox credentials, so it has no
+// default and "" is returned, as for unknown environments
+func (e Environment) BaseURL() string {
+	if e == EnvironmentProduction {
+		return productionAPI + "/" + DefaultAPIVersion
+	}
+	return ""
+}
+
+// IsTest reports whether the environment moves no real money
+func (e Environment) IsTest() bool {
+	return e == EnvironmentSandbox
+}
+
+// parseEnvironment normalizes an environment name, e.g. from
+// PAYRIFF_ENVIRONMENT
+func parseEnvironment(s string) (Environment, error) {
+	e := Environment(strings.ToLower(strings.TrimSpace(s)))
+	if e != EnvironmentProduction && e != EnvironmentSandbox {
+		return e, &ConfigError{Field: "Environment", Problem: fmt.Sprintf("%q is not production or sandbox", s)}
+	}
+	return e, nil
+}
+
+// checkEnvironment rejects a base URL that does not belong to the
+// environment, so sandbox responses flagged TestMode never come from
+// production
+func checkEnvironment(e Environment, baseURL string) error {
+	if e != EnvironmentSandbox {
+		return nil
+	}
+	if baseURL == "" {
+		return &ConfigError{Field: "BaseURL", Problem: "is required for the sandbox; use the sandbox host issued with your test credentials"}
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil // reported by Validate
+	}
+	if prod, _ := url.Parse(productionAPI); strings.EqualFold(u.Hostname(), prod.Hostname()) {
+		return &ConfigError{Field: "BaseURL", Problem: fmt.Sprintf("%q is the production gateway, but Environment is sandbox", baseURL)}
+	}
+	return nil
+}
+
+// Environment returns the environment the SDK was configured for
+func (s *SDK) Environment() Environment {
+	return s.environment
+}
@@ -0,0 +1,113 @@
+package payriff
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrUnauthorized matches API errors for rejected credentials
+	ErrUnauthorized = errors.New("payriff: unauthorized")
+	// ErrInvalidToken matches API errors for missing or invalid tokens
+	ErrInvalidToken = errors.New("payriff: invalid token")
+	// ErrInvalidParameters matches API errors for invalid request parameters
+	ErrInvalidParameters = errors.New("payriff: invalid parameters")
+)
+
+// APIError is a response the gateway answered with a non-success result
+// code. It matches ErrUnauthorized, ErrInvalidToken and ErrInvalidParameters
+// with errors.Is according to its code
+type APIError struct {
+	Code            ResultCode
+	Message         string
+	InternalMessage string
+	Route           string
+	ResponseID      string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("payriff: %s %s", e.Code, e.Message)
+	if e.InternalMessage != "" {
+		msg += " (" + e.InternalMessage + ")"
+	}
+	return msg
+}
+
+// Is reports whether target is the sentinel for e's result code
+func (e *APIError) Is(target error) bool {
+	switch e.Code {
+	case ResultCodeUnauthorized:
+		return target == ErrUnauthorized
+	case ResultCodeTokenNotPresent, ResultCodeInvalidToken:
+		return target == ErrInvalidToken
+	case ResultCodeInvalidParameters:
+		return target == ErrInvalidParameters
+	}
+	return false
+}
+
+// maxErrorBody caps the body captured by an HTTPError
+const maxErrorBody = 4 << 10
+
+// HTTPError is a non-2xx response that does not carry a gateway envelope,
+// such as an HTML error page from a proxy or load balancer. It matches
+// ErrUnauthorized with errors.Is for 401 responses
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	// Body holds the start of the response body, capped at 4 KiB
+	Body []byte
+	// Truncated is set when Body was capped
+	Truncated bool
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("payriff: gateway returned %s", e.Status)
+	if ct := e.Header.Get("Content-Type"); ct != "" {
+		msg += " (" + ct + ")"
+	}
+	return msg
+}
+
+// Is reports whether target is the sentinel for e's status
+func (e *HTTPError) Is(target error) bool {
+	return e.StatusCode == http.StatusUnauthorized && target == ErrUnauthorized
+}
+
+// Temporary reports whether the status signals a transient gateway
+// failure that is worth retrying
+func (e *HTTPError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// newAPIError builds an APIError from response metadata
+func newAPIError(resp *Response) *APIError {
+	e := &APIError{
+		Code:       resp.Code,
+		Message:    resp.Message,
+		Route:      resp.Route,
+		ResponseID: resp.ResponseID,
+	}
+	if resp.InternalMessage != nil {
+		e.InternalMessage = *resp.InternalMessage
+	}
+	return e
+}
+
+// checkResult returns a *MaintenanceError for maintenance notices, and an
+// *APIError for other non-success responses when Config.ErrorOnFailure is set
+func (s *SDK) checkResult(resp *Response) error {
+	if err := s.maintenanceFromResponse(resp); err != nil {
+		return err
+	}
+	if s.errorOnFailure && !resp.IsSuccessful() {
+		return newAPIError(resp)
+	}
+	return nil
+}
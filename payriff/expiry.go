@@ -0,0 +1,183 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CardExpiry represents the expiry month and year of a card
+type CardExpiry struct {
+	Month int
+	Year  int
+}
+
+// ParseCardExpiry parses expiry dates in MM/YY or MM/YYYY form
+func ParseCardExpiry(s string) (CardExpiry, error) {
+	month, year, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return CardExpiry{}, fmt.Errorf("payriff: invalid card expiry %q", s)
+	}
+
+	if len(month) == 0 || len(month) > 2 || !isDigits(month) {
+		return CardExpiry{}, fmt.Errorf("payriff: invalid card expiry month %q", month)
+	}
+	m, err := strconv.Atoi(month)
+	if err != nil {
+		return CardExpiry{}, fmt.Errorf("payriff: invalid card expiry month %q: %w", month, err)
+	}
+	if m < 1 || m > 12 {
+		return CardExpiry{}, fmt.Errorf("payriff: invalid card expiry month %q", month)
+	}
+
+	if (len(year) != 2 && len(year) != 4) || !isDigits(year) {
+		return CardExpiry{}, fmt.Errorf("payriff: invalid card expiry year %q, want 2 or 4 digits", year)
+	}
+	y, err := strconv.Atoi(year)
+	if err != nil {
+		return CardExpiry{}, fmt.Errorf("payriff: invalid card expiry year %q: %w", year, err)
+	}
+	if len(year) == 2 {
+		y += 2000
+	}
+
+	return CardExpiry{Month: m, Year: y}, nil
+}
+
+// ExpiresAt returns the first instant at which the card is no longer valid
+func (e CardExpiry) ExpiresAt() time.Time {
+	return time.Date(e.Year, time.Month(e.Month)+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// String formats the expiry as MM/YY
+func (e CardExpiry) String() string {
+	return fmt.Sprintf("%02d/%02d", e.Month, e.Year%100)
+}
+
+// TrackedCard is a saved card watched by a CardExpiryMonitor
+type TrackedCard struct {
+	CardUUID   CardUUID
+	MaskedPan  string
+	CustomerID string
+	Expiry     CardExpiry
+}
+
+// CardExpiryMonitor tracks saved card expiry dates and emits
+// NotificationCardExpiring through a Notifier ahead of expiry
+type CardExpiryMonitor struct {
+	// Notifier receives "card expiring soon" notifications
+	Notifier Notifier
+	// Window is how long before expiry a card is reported, defaults to 30 days
+	Window time.Duration
+
+	mu       sync.Mutex
+	cards    map[CardUUID]TrackedCard
+	notified map[CardUUID]CardExpiry
+}
+
+// Track registers or updates a saved card
+func (m *CardExpiryMonitor) Track(card TrackedCard) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cards == nil {
+		m.cards = make(map[CardUUID]TrackedCard)
+		m.notified = make(map[CardUUID]CardExpiry)
+	}
+	if prev, ok := m.cards[card.CardUUID]; ok && prev.Expiry != card.Expiry {
+		delete(m.notified, card.CardUUID)
+	}
+	m.cards[card.CardUUID] = card
+}
+
+// Untrack stops watching a card, e.g. after it was deleted
+func (m *CardExpiryMonitor) Untrack(cardUUID CardUUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.cards, cardUUID)
+	delete(m.notified, cardUUID)
+}
+
+// Expiring returns tracked cards that expire within the window after now
+func (m *CardExpiryMonitor) Expiring(now time.Time) []TrackedCard {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var cards []TrackedCard
+	for _, card := range m.cards {
+		if card.Expiry.ExpiresAt().Before(now.Add(m.window())) {
+			cards = append(cards, card)
+		}
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		return cards[i].Expiry.ExpiresAt().Before(cards[j].Expiry.ExpiresAt())
+	})
+	return cards
+}
+
+// Check notifies about every expiring card that has not been reported yet
+func (m *CardExpiryMonitor) Check(ctx context.Context, now time.Time) error {
+	if m.Notifier == nil {
+		return errors.New("payriff: card expiry monitor has no notifier")
+	}
+
+	var errs []error
+	for _, card := range m.Expiring(now) {
+		m.mu.Lock()
+		reported, ok := m.notified[card.CardUUID]
+		m.mu.Unlock()
+		if ok && reported == card.Expiry {
+			continue
+		}
+
+		n := Notification{
+			Kind:    NotificationCardExpiring,
+			Subject: fmt.Sprintf("Card %s expires %s", card.MaskedPan, card.Expiry),
+			Body:    fmt.Sprintf("Saved card %s of customer %s expires at the end of %s.", card.MaskedPan, card.CustomerID, card.Expiry),
+			Data: map[string]any{
+				"cardUuid":   card.CardUUID,
+				"customerId": card.CustomerID,
+				"expiresAt":  card.Expiry.ExpiresAt(),
+			},
+		}
+		if err := safeCall(func() error { return m.Notifier.Notify(ctx, n) }); err != nil {
+			errs = append(errs, fmt.Errorf("failed to notify about card %s: %w", card.CardUUID, err))
+			continue
+		}
+
+		m.mu.Lock()
+		m.notified[card.CardUUID] = card.Expiry
+		m.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Run checks for expiring cards every interval until ctx is done
+func (m *CardExpiryMonitor) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Failed notifications are retried on the next tick
+		_ = m.Check(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *CardExpiryMonitor) window() time.Duration {
+	if m.Window > 0 {
+		return m.Window
+	}
+	return 30 * 24 * time.Hour
+}
@@ -0,0 +1,75 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrUnknownEndpoint is returned by Invoke for a path no registered
+// endpoint matches
+var ErrUnknownEndpoint = errors.New("payriff: unknown endpoint")
+
+// RegisterEndpoint adds an endpoint the SDK has no wrapper for, such as one
+// from a bespoke gateway agreement, so that Invoke can call it. Registered
+// endpoints go through the same key selection, feature checks, retries,
+// idempotency, logging, metrics and audit as the built-in ones, and are
+// listed by Endpoints. Retry defaults to RetrySafe for GET and to
+// RetryWithKey otherwise. Register endpoints during initialization, e.g.
+// from an init function of the extending package
+func RegisterEndpoint(info EndpointInfo) error {
+	if info.Name == "" || info.Method == "" || !strings.HasPrefix(info.Path, "/") {
+		return errors.New("payriff: endpoint needs a name, a method and a path starting with /")
+	}
+	if info.Scope == "" {
+		info.Scope = ScopeSecret
+	}
+	if info.Retry == "" {
+		info.Retry = RetryWithKey
+		if info.Method == http.MethodGet {
+			info.Retry = RetrySafe
+		}
+	}
+
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+
+	for _, e := range endpoints {
+		if e.Name == info.Name {
+			return fmt.Errorf("payriff: endpoint %s is already registered", info.Name)
+		}
+		if e.Pattern() == info.Pattern() {
+			return fmt.Errorf("payriff: endpoint %s is already registered as %s", info.Pattern(), e.Name)
+		}
+	}
+	endpoints = append(endpoints, info)
+	return nil
+}
+
+// Invoke calls the registered endpoint addressed by method and path, which
+// is a concrete path such as /loyalty/ORD-1, and decodes its payload as T.
+// body is encoded as JSON, nil sends none
+func Invoke[T any](ctx context.Context, s *SDK, method, path string, body any, opts ...RequestOption) (*ApiResponse[T], error) {
+	info, ok := LookupEndpoint(method, path)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", ErrUnknownEndpoint, method, path)
+	}
+
+	ctx, _, cancel := withOptions(ctx, opts)
+	defer cancel()
+
+	if method != http.MethodGet {
+		if err := s.checkWritable(); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := s.makeRequest(ctx, path, method, info.Scope, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse[T](s, info.Pattern(), resp)
+}
@@ -0,0 +1,113 @@
+package payriff
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedFeature is returned for calls using a feature the merchant
+// account does not have
+var ErrUnsupportedFeature = errors.New("payriff: feature not supported")
+
+// Feature is a gateway capability that may or may not be enabled for a
+// merchant account
+type Feature string
+
+const (
+	FeaturePreAuth      Feature = "PRE_AUTH"
+	FeatureCardSave     Feature = "CARD_SAVE"
+	FeatureInstallments Feature = "INSTALLMENTS"
+	FeatureAutoPay      Feature = "AUTO_PAY"
+	FeatureRefunds      Feature = "REFUNDS"
+	FeatureInvoices     Feature = "INVOICES"
+	FeatureTopup        Feature = "TOPUP"
+	FeatureMPAY         Feature = "MPAY"
+)
+
+// KnownFeatures lists the features the SDK checks
+var KnownFeatures = []Feature{
+	FeaturePreAuth,
+	FeatureCardSave,
+	FeatureInstallments,
+	FeatureAutoPay,
+	FeatureRefunds,
+	FeatureInvoices,
+	FeatureTopup,
+	FeatureMPAY,
+}
+
+// FeatureSet enables or disables features. Features missing from the set
+// fall back to the capability matrix
+type FeatureSet map[Feature]bool
+
+// FeatureError is returned when a call needs a feature that is disabled
+type FeatureError struct {
+	Feature Feature
+	// Operation is the SDK method or endpoint that needs the feature
+	Operation string
+}
+
+func (e *FeatureError) Error() string {
+	return fmt.Sprintf("payriff: %s needs feature %s, which is not enabled for this merchant", e.Operation, e.Feature)
+}
+
+func (e *FeatureError) Unwrap() error {
+	return ErrUnsupportedFeature
+}
+
+// Supports reports whether the merchant account has feature f. The gateway
+// API does not expose the merchant's features, so Config.Features is the
+// only source; features it does not list are derived from the capability
+// matrix, defaulting to supported
+func (s *SDK) Supports(f Feature) bool {
+	if enabled, ok := s.featureOverrides[f]; ok {
+		return enabled
+	}
+
+	switch f {
+	case FeaturePreAuth:
+		_, ok := s.capabilities[OperationPreAuth]
+		return ok
+	case FeatureCardSave:
+		for _, c := range s.capabilities {
+			if c.CardSave {
+				return true
+			}
+		}
+		return false
+	case FeatureInstallments:
+		for _, c := range s.capabilities {
+			if c.Installments {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// requireFeatures returns a *FeatureError for the first of fs that is not
+// supported
+func (s *SDK) requireFeatures(operation string, fs ...Feature) error {
+	for _, f := range fs {
+		if !s.Supports(f) {
+			return &FeatureError{Feature: f, Operation: operation}
+		}
+	}
+	return nil
+}
+
+// operationFeatures returns the features an order or charge needs
+func operationFeatures(operation Operation, cardSave, installment bool) []Feature {
+	var fs []Feature
+	if operation.IsPreAuth() {
+		fs = append(fs, FeaturePreAuth)
+	}
+	if cardSave {
+		fs = append(fs, FeatureCardSave)
+	}
+	if installment {
+		fs = append(fs, FeatureInstallments)
+	}
+	return fs
+}
@@ -0,0 +1,179 @@
+package payriff
+
+import (
+	"sort"
+	"time"
+)
+
+// orderDateLayouts are the layouts CreatedDate is sent in
+var orderDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", time.DateTime}
+
+// parseOrderDate parses an order's CreatedDate in loc, for layouts
+// without a zone
+func parseOrderDate(s string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range orderDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// SettlementForecaster estimates upcoming payouts from orders not yet
+// settled, for cash flow planning
+type SettlementForecaster struct {
+	// Delay is the number of business days from payment to payout,
+	// defaults to 1
+	Delay int
+	// Weekends counts Saturdays and Sundays as business days
+	Weekends bool
+	// HoldRatio is the share of pre-authorized amounts expected to be
+	// completed, defaults to 1. Holds are assumed to be completed on the
+	// first forecast day
+	HoldRatio float64
+	// Rates is the commission for orders the gateway reports no
+	// CommissionRate for. Orders without either are forecast without
+	// commission
+	Rates RateTable
+	// Location is where payout days start, defaults to time.Local
+	Location *time.Location
+}
+
+// PayoutDay is the forecast payout of one day in one currency. Amounts
+// are in the settlement currency
+type PayoutDay struct {
+	Date       time.Time
+	Currency   Currency
+	Gross      Amount
+	Commission Amount
+	// Net is Gross less Commission, what is expected to be paid out
+	Net Amount
+	// Held is the part of Net from pre-authorizations not yet completed
+	Held   Amount
+	Orders int
+}
+
+// SettlementForecast is the payout forecast of a period
+type SettlementForecast struct {
+	// Days holds the days with payouts, sorted by date and currency
+	Days []PayoutDay
+}
+
+// Total returns the net payout forecast in currency
+func (f SettlementForecast) Total(currency Currency) Amount {
+	var total Amount
+	for _, d := range f.Days {
+		if d.Currency == currency {
+			total = total.Add(d.Net)
+		}
+	}
+	return total
+}
+
+// Forecast estimates the payouts of the days days starting at from for
+// unsettled orders. Approved orders are paid out Delay business days
+// after they were created, pre-authorized ones after the first forecast
+// day. Payouts already due are expected on the first day, and other
+// statuses are ignored
+func (f *SettlementForecaster) Forecast(unsettled []OrderInfo, from time.Time, days int) SettlementForecast {
+	loc := f.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	delay := f.Delay
+	if delay <= 0 {
+		delay = 1
+	}
+	holdRatio := f.HoldRatio
+	if holdRatio <= 0 {
+		holdRatio = 1
+	}
+
+	first := startOfDay(from.In(loc))
+	last := first.AddDate(0, 0, days)
+
+	type key struct {
+		day      time.Time
+		currency Currency
+	}
+	byDay := make(map[key]*PayoutDay)
+	for _, o := range unsettled {
+		held := o.PaymentStatus.IsCapturable()
+		if o.PaymentStatus != StatusApproved && !held {
+			continue
+		}
+
+		paid := first
+		if !held {
+			if t, ok := parseOrderDate(o.CreatedDate, loc); ok {
+				paid = startOfDay(t.In(loc))
+			}
+		}
+		day := f.addBusinessDays(paid, delay)
+		if day.Before(first) {
+			day = first
+		}
+		if !day.Before(last) {
+			continue
+		}
+
+		amount, currency := o.Settlement()
+		if held {
+			amount *= holdRatio
+		}
+		rate, ok := f.Rates.Lookup(o)
+		if o.CommissionRate != nil {
+			rate, ok = *o.CommissionRate, true
+		}
+		gross := AmountOf(amount)
+		var commission Amount
+		if ok {
+			commission = AmountOf(amount * rate / 100)
+		}
+
+		k := key{day, currency}
+		d, exists := byDay[k]
+		if !exists {
+			d = &PayoutDay{Date: day, Currency: currency}
+			byDay[k] = d
+		}
+		net := gross.Sub(commission)
+		d.Gross = d.Gross.Add(gross)
+		d.Commission = d.Commission.Add(commission)
+		d.Net = d.Net.Add(net)
+		if held {
+			d.Held = d.Held.Add(net)
+		}
+		d.Orders++
+	}
+
+	forecast := SettlementForecast{Days: make([]PayoutDay, 0, len(byDay))}
+	for _, d := range byDay {
+		forecast.Days = append(forecast.Days, *d)
+	}
+	sort.Slice(forecast.Days, func(i, j int) bool {
+		a, b := forecast.Days[i], forecast.Days[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.Currency < b.Currency
+	})
+	return forecast
+}
+
+// addBusinessDays returns the day n business days after day
+func (f *SettlementForecaster) addBusinessDays(day time.Time, n int) time.Time {
+	for n > 0 {
+		day = day.AddDate(0, 0, 1)
+		if f.Weekends || (day.Weekday() != time.Saturday && day.Weekday() != time.Sunday) {
+			n--
+		}
+	}
+	return day
+}
+
+// startOfDay returns midnight of t's day in t's location
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,183 @@
+package payriff
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on events forwarded by a Forwarder
+const (
+	HeaderForwardSignature = "X-Payriff-Forward-Signature"
+)
+
+var (
+	// ErrMissingSignature is returned when a forwarded event or callback carries no signature
+	ErrMissingSignature = errors.New("payriff: missing event signature")
+	// ErrInvalidSignature is returned when a forwarded event or callback signature does not match
+	ErrInvalidSignature = errors.New("payriff: invalid event signature")
+	// ErrUnknownSigningKey is returned when a signature references an unknown key ID
+	ErrUnknownSigningKey = errors.New("payriff: unknown signing key")
+	// ErrSignatureExpired is returned when a signature timestamp is outside the tolerance
+	ErrSignatureExpired = errors.New("payriff: event signature expired")
+)
+
+// SigningKey is a shared secret used to sign forwarded events
+type SigningKey struct {
+	ID     string
+	Secret []byte
+}
+
+// EventSigner signs forwarded event payloads with HMAC-SHA256. The first key
+// signs new events, so rotating means prepending a new key on the signer
+// while verifiers accept both during the overlap
+type EventSigner struct {
+	Keys []SigningKey
+}
+
+// NewEventSigner creates a signer using the first key as the active key
+func NewEventSigner(keys ...SigningKey) *EventSigner {
+	return &EventSigner{Keys: keys}
+}
+
+// Sign returns the signature header value for payload
+func (s *EventSigner) Sign(payload []byte) (string, error) {
+	if len(s.Keys) == 0 {
+		return "", errors.New("payriff: event signer has no keys")
+	}
+
+	key := s.Keys[0]
+	ts := time.Now().Unix()
+	return fmt.Sprintf("t=%d,kid=%s,v1=%s", ts, key.ID, signEvent(key.Secret, ts, payload)), nil
+}
+
+// EventVerifier verifies signatures produced by an EventSigner
+type EventVerifier struct {
+	// Keys holds every key that is currently accepted
+	Keys []SigningKey
+	// Tolerance is the maximum signature age, defaults to five minutes
+	Tolerance time.Duration
+}
+
+// NewEventVerifier creates a verifier accepting any of the given keys
+func NewEventVerifier(keys ...SigningKey) *EventVerifier {
+	return &EventVerifier{Keys: keys}
+}
+
+// Verify checks the signature header value against payload
+func (v *EventVerifier) Verify(payload []byte, signature string) error {
+	if signature == "" {
+		return ErrMissingSignature
+	}
+
+	var ts int64
+	var kid, mac string
+	for _, part := range strings.Split(signature, ",") {
+		k, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			parsed, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%w: bad timestamp: %w", ErrInvalidSignature, err)
+			}
+			ts = parsed
+		case "kid":
+			kid = val
+		case "v1":
+			mac = val
+		}
+	}
+	if mac == "" || ts == 0 {
+		return ErrMissingSignature
+	}
+
+	tolerance := v.Tolerance
+	if tolerance <= 0 {
+		tolerance = 5 * time.Minute
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+		return ErrSignatureExpired
+	}
+
+	for _, key := range v.Keys {
+		if key.ID != kid {
+			continue
+		}
+		if hmac.Equal([]byte(mac), []byte(signEvent(key.Secret, ts, payload))) {
+			return nil
+		}
+		return ErrInvalidSignature
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownSigningKey, kid)
+}
+
+// VerifyRequest reads and verifies a forwarded event request, returning its body
+func (v *EventVerifier) VerifyRequest(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event body: %w", err)
+	}
+	if err := v.Verify(body, r.Header.Get(HeaderForwardSignature)); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// Forwarder fans out event payloads to internal services, signing each one
+type Forwarder struct {
+	Signer  *EventSigner
+	Targets []string
+	// Client defaults to a client with DefaultTimeout
+	Client *http.Client
+}
+
+// Forward posts payload to every target and returns the joined failures
+func (f *Forwarder) Forward(ctx context.Context, payload []byte) error {
+	signature, err := f.Signer.Sign(payload)
+	if err != nil {
+		return err
+	}
+
+	client := f.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	var errs []error
+	for _, target := range f.Targets {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create forward request for %s: %w", target, err))
+			continue
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(HeaderForwardSignature, signature)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to forward event to %s: %w", target, err))
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			errs = append(errs, fmt.Errorf("failed to forward event to %s: unexpected status %s", target, resp.Status))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func signEvent(secret []byte, ts int64, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%d.", ts)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,192 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrGatewayUnavailable is returned by write methods in degraded mode while
+// the gateway is considered unavailable
+var ErrGatewayUnavailable = errors.New("payriff: gateway unavailable")
+
+const (
+	// defaultDegradedThreshold is the number of consecutive transport
+	// failures that flag the gateway unavailable
+	defaultDegradedThreshold = 3
+	// defaultDegradedRecovery is how long the gateway stays flagged
+	// unavailable without a successful probe
+	defaultDegradedRecovery = 30 * time.Second
+	// maxStaleOrders bounds the order cache used in degraded mode
+	maxStaleOrders = 10000
+)
+
+// health tracks gateway availability and the read cache used in degraded mode
+type health struct {
+	threshold int
+	recovery  time.Duration
+
+	mu sync.RWMutex
+	// failures counts consecutive transport failures
+	failures int
+	// downUntil is when the unavailable flag expires, zero while available
+	downUntil time.Time
+	orders    map[OrderID]OrderInfo
+	// cached holds the cached order IDs, oldest first
+	cached []OrderID
+}
+
+func newHealth(threshold int, recovery time.Duration) *health {
+	if threshold <= 0 {
+		threshold = defaultDegradedThreshold
+	}
+	if recovery <= 0 {
+		recovery = defaultDegradedRecovery
+	}
+	return &health{threshold: threshold, recovery: recovery}
+}
+
+// Available reports whether the gateway is currently considered available.
+// The gateway is flagged unavailable after Config.DegradedThreshold
+// consecutive transport failures, until a probe succeeds or
+// Config.DegradedRecovery has passed
+func (s *SDK) Available() bool {
+	s.health.mu.RLock()
+	defer s.health.mu.RUnlock()
+
+	return s.health.downUntil.IsZero() || time.Now().After(s.health.downUntil)
+}
+
+// Probe checks gateway reachability once and updates availability. Any
+// response below 500 counts as available
+func (s *SDK) Probe(ctx context.Context) error {
+	err := s.probe(ctx)
+	if ctx.Err() != nil {
+		return err
+	}
+
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	s.health.failures = 0
+	if err != nil {
+		s.health.downUntil = time.Now().Add(s.health.recovery)
+	} else {
+		s.health.downUntil = time.Time{}
+	}
+	return err
+}
+
+// StartHealthProbe probes the gateway every interval until ctx is done
+func (s *SDK) StartHealthProbe(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			_ = s.Probe(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *SDK) probe(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.apiURL(""), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create probe request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrGatewayUnavailable, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: probe returned %s", ErrGatewayUnavailable, resp.Status)
+	}
+	return nil
+}
+
+// checkWritable fails fast when degraded mode is on and the gateway is down
+func (s *SDK) checkWritable() error {
+	if s.degradedMode && !s.Available() {
+		return ErrGatewayUnavailable
+	}
+	return nil
+}
+
+// markTransportFailure counts a request that never got a response, and
+// flags the gateway unavailable after enough consecutive failures. Requests
+// ended by the caller's context (context.Canceled or DeadlineExceeded) are
+// not counted, while Config.Timeout expiring is
+func (s *SDK) markTransportFailure(ctx context.Context) {
+	if !s.degradedMode || ctx.Err() != nil {
+		return
+	}
+
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	s.health.failures++
+	if s.health.failures >= s.health.threshold {
+		s.health.failures = 0
+		s.health.downUntil = time.Now().Add(s.health.recovery)
+	}
+}
+
+// markTransportSuccess resets the failure count after a response
+func (s *SDK) markTransportSuccess() {
+	if !s.degradedMode {
+		return
+	}
+
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	s.health.failures = 0
+}
+
+// cacheOrder keeps the last known state of an order for degraded mode,
+// evicting the oldest orders beyond maxStaleOrders
+func (s *SDK) cacheOrder(info OrderInfo) {
+	if !s.degradedMode {
+		return
+	}
+
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+
+	if s.health.orders == nil {
+		s.health.orders = make(map[OrderID]OrderInfo)
+	}
+	if _, ok := s.health.orders[info.OrderID]; !ok {
+		s.health.cached = append(s.health.cached, info.OrderID)
+		if len(s.health.cached) > maxStaleOrders {
+			delete(s.health.orders, s.health.cached[0])
+			s.health.cached = s.health.cached[1:]
+		}
+	}
+	s.health.orders[info.OrderID] = info
+}
+
+// staleOrder returns the cached order marked stale when degraded mode is on
+// and the gateway is unavailable
+func (s *SDK) staleOrder(orderID OrderID) (*ApiResponse[OrderInfo], bool) {
+	if !s.degradedMode || s.Available() {
+		return nil, false
+	}
+
+	s.health.mu.RLock()
+	defer s.health.mu.RUnlock()
+
+	info, ok := s.health.orders[orderID]
+	if !ok {
+		return nil, false
+	}
+	return &ApiResponse[OrderInfo]{Payload: info, Stale: true, TestMode: s.environment.IsTest()}, true
+}
@@ -0,0 +1,53 @@
+package payriff
+
+import (
+	"errors"
+	"time"
+)
+
+// Hooks are optional callbacks the SDK invokes to report on its behavior
+type Hooks struct {
+	// OnRetry is called before every retry attempt
+	OnRetry func(RetryEvent)
+	// OnError receives errors the SDK cannot return to a caller, such as
+	// panics recovered from other hooks as *PanicError
+	OnError func(error)
+	// OnShadowDiff receives the comparison of every mirrored read request
+	OnShadowDiff func(ShadowDiff)
+	// OnDeprecation is called once per deprecated API the first time it is
+	// used. Notices go to the standard logger when unset
+	OnDeprecation func(Deprecation)
+	// OnDrift receives responses whose fields differ from the SDK's types
+	// when Config.DetectDrift is set
+	OnDrift func(DriftReport)
+}
+
+// RetryCause classifies why an attempt is retried
+type RetryCause string
+
+const (
+	// RetryCauseNetwork means the request never got a response
+	RetryCauseNetwork RetryCause = "network"
+	// RetryCauseGateway means the gateway answered with a transient error status
+	RetryCauseGateway RetryCause = "gateway"
+)
+
+// RetryEvent describes a retry that is about to happen
+type RetryEvent struct {
+	Method   string
+	Endpoint string
+	// Attempt is the number of the attempt about to start, starting at 2
+	Attempt int
+	Delay   time.Duration
+	Cause   RetryCause
+	Err     error
+}
+
+// retryCause classifies a transient error
+func retryCause(err error) RetryCause {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return RetryCauseGateway
+	}
+	return RetryCauseNetwork
+}
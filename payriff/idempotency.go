@@ -0,0 +1,117 @@
+package payriff
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// HeaderIdempotencyKey carries the idempotency key of a mutating request
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// ErrIdempotencyKeyReused is returned when an idempotency key is sent again
+// with a different request body
+var ErrIdempotencyKeyReused = errors.New("payriff: idempotency key reused with a different request")
+
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey returns a context that sends key as the idempotency
+// key of the request made with it. The gateway processes a key once, so
+// requests carrying a key are also retried under the RetryPolicy
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+// idempotencyKey returns the key set with WithIdempotencyKey
+func idempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyCtx{}).(string)
+	return key
+}
+
+// IdempotencyKeyGenerator creates keys for mutating calls made without one
+type IdempotencyKeyGenerator interface {
+	NewKey(ctx context.Context, method, endpoint string) (string, error)
+}
+
+// IdempotencyKeyGeneratorFunc adapts a function to the IdempotencyKeyGenerator interface
+type IdempotencyKeyGeneratorFunc func(ctx context.Context, method, endpoint string) (string, error)
+
+// NewKey calls f(ctx, method, endpoint)
+func (f IdempotencyKeyGeneratorFunc) NewKey(ctx context.Context, method, endpoint string) (string, error) {
+	return f(ctx, method, endpoint)
+}
+
+// UUIDKeys generates random UUIDv4 idempotency keys
+var UUIDKeys IdempotencyKeyGenerator = IdempotencyKeyGeneratorFunc(func(ctx context.Context, method, endpoint string) (string, error) {
+	return NewIdempotencyKey()
+})
+
+// NewIdempotencyKey returns a random UUIDv4
+func NewIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// IdempotencyStore remembers the body hash sent with each idempotency key
+type IdempotencyStore interface {
+	// Remember stores hash for key unless the key is known, and returns
+	// the hash stored for key
+	Remember(ctx context.Context, key, hash string) (string, error)
+}
+
+// idempotent attaches an idempotency key to ctx for a mutating call,
+// generating one when configured, and checks it against the store
+func (s *SDK) idempotent(ctx context.Context, method, endpoint string, payload []byte) (context.Context, error) {
+	key := idempotencyKey(ctx)
+	if key == "" && s.idempotencyKeys != nil {
+		err := safeCall(func() (err error) {
+			key, err = s.idempotencyKeys.NewKey(ctx, method, endpoint)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		ctx = WithIdempotencyKey(ctx, key)
+	}
+	if key == "" || s.idempotencyStore == nil {
+		return ctx, nil
+	}
+
+	hash := ContentHash(payload)
+	stored, err := s.idempotencyStore.Remember(ctx, key, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record idempotency key: %w", err)
+	}
+	if stored != hash {
+		return nil, fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, key)
+	}
+	return ctx, nil
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore
+type MemoryIdempotencyStore struct {
+	mu     sync.Mutex
+	hashes map[string]string
+}
+
+// Remember implements IdempotencyStore
+func (m *MemoryIdempotencyStore) Remember(ctx context.Context, key, hash string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.hashes == nil {
+		m.hashes = make(map[string]string)
+	}
+	if stored, ok := m.hashes[key]; ok {
+		return stored, nil
+	}
+	m.hashes[key] = hash
+	return hash, nil
+}
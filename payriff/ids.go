@@ -0,0 +1,68 @@
+package payriff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidOrderID is returned when an order ID is malformed
+	ErrInvalidOrderID = errors.New("payriff: invalid order ID")
+	// ErrInvalidCardUUID is returned when a card UUID is malformed
+	ErrInvalidCardUUID = errors.New("payriff: invalid card UUID")
+)
+
+// OrderID identifies a gateway order. A distinct type keeps order IDs and
+// card UUIDs from being swapped in calls
+type OrderID string
+
+// ParseOrderID validates an order ID received from outside the SDK, e.g.
+// from a URL or database. It must be non-empty and safe in a URL path
+func ParseOrderID(s string) (OrderID, error) {
+	if s == "" || len(s) > 128 || strings.ContainsAny(s, "/?#% \t\r\n") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidOrderID, s)
+	}
+	return OrderID(s), nil
+}
+
+// String returns the ID
+func (id OrderID) String() string {
+	return string(id)
+}
+
+// CardUUID identifies a card saved for AutoPay
+type CardUUID string
+
+// ParseCardUUID validates a card UUID in its canonical 8-4-4-4-12 form
+func ParseCardUUID(s string) (CardUUID, error) {
+	if !isUUID(s) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCardUUID, s)
+	}
+	return CardUUID(strings.ToLower(s)), nil
+}
+
+// String returns the UUID
+func (id CardUUID) String() string {
+	return string(id)
+}
+
+// isUUID reports whether s is a hex UUID with dashes at the usual positions
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
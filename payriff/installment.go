@@ -0,0 +1,147 @@
+package payriff
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrInstallmentNotOffered is returned when a rate table has no rate for
+// the requested bank and period
+var ErrInstallmentNotOffered = errors.New("payriff: installment not offered")
+
+// InstallmentType is the kind of installment plan requested
+type InstallmentType string
+
+const (
+	// InstallmentTypeCard splits the payment on the shopper's bank card
+	// (taksit), the only type offered on most merchant accounts
+	InstallmentTypeCard InstallmentType = "CARD"
+)
+
+// InstallmentPeriod is the number of monthly payments
+type InstallmentPeriod int
+
+const (
+	InstallmentPeriod2  InstallmentPeriod = 2
+	InstallmentPeriod3  InstallmentPeriod = 3
+	InstallmentPeriod6  InstallmentPeriod = 6
+	InstallmentPeriod9  InstallmentPeriod = 9
+	InstallmentPeriod12 InstallmentPeriod = 12
+	InstallmentPeriod18 InstallmentPeriod = 18
+	InstallmentPeriod24 InstallmentPeriod = 24
+)
+
+// Installment requests payment in installments on an order or charge
+type Installment struct {
+	// Type defaults to InstallmentTypeCard
+	Type   InstallmentType   `json:"type,omitempty"`
+	Period InstallmentPeriod `json:"period"`
+	// Banks restricts the payment to cards of these banks, e.g. those a
+	// quote was made for. Empty allows every bank of the contract
+	Banks []string `json:"banks,omitempty"`
+}
+
+// normalize checks the installment before it is sent and returns a copy
+// with defaults applied, leaving the caller's value untouched
+func (i *Installment) normalize() (*Installment, error) {
+	if i.Period <= 0 {
+		return nil, fmt.Errorf("payriff: invalid installment period %d", i.Period)
+	}
+	c := *i
+	if c.Type == "" {
+		c.Type = InstallmentTypeCard
+	}
+	return &c, nil
+}
+
+// InstallmentRate is the shopper surcharge for paying in installments with
+// a bank's cards over a number of months
+type InstallmentRate struct {
+	Bank string
+	// Months is the installment period
+	Months int
+	// Percent is the surcharge added to the amount, e.g. 4.5 for 4.5%
+	Percent float64
+	// MinAmount is the smallest amount the bank allows in installments
+	MinAmount Amount
+}
+
+// InstallmentRates is a table of installment surcharges, kept in sync with
+// your Payriff contract
+type InstallmentRates []InstallmentRate
+
+// InstallmentQuote is the shopper-facing breakdown of an installment payment
+type InstallmentQuote struct {
+	Bank      string
+	Months    int
+	Amount    Amount
+	Surcharge Amount
+	Total     Amount
+	// Monthly is every payment but the last. The last payment also takes
+	// the minor units left over by the split and is reported as LastPayment,
+	// so the payments add up to Total exactly
+	Monthly     Amount
+	LastPayment Amount
+}
+
+// Installment returns the installment request for the quoted plan
+func (q InstallmentQuote) Installment() *Installment {
+	return &Installment{
+		Type:   InstallmentTypeCard,
+		Period: InstallmentPeriod(q.Months),
+		Banks:  []string{q.Bank},
+	}
+}
+
+// Rate returns the rate for bank and months. Bank names compare case-insensitively
+func (t InstallmentRates) Rate(bank string, months int) (InstallmentRate, bool) {
+	for _, r := range t {
+		if r.Months == months && strings.EqualFold(r.Bank, bank) {
+			return r, true
+		}
+	}
+	return InstallmentRate{}, false
+}
+
+// Periods returns the installment periods offered by bank for amount
+func (t InstallmentRates) Periods(bank string, amount Amount) []int {
+	var months []int
+	for _, r := range t {
+		if strings.EqualFold(r.Bank, bank) && amount.Cmp(r.MinAmount) >= 0 {
+			months = append(months, r.Months)
+		}
+	}
+	return months
+}
+
+// Quote computes the total and monthly payments for amount paid with
+// bank's cards over months
+func (t InstallmentRates) Quote(amount Amount, bank string, months int) (InstallmentQuote, error) {
+	if !amount.IsPositive() {
+		return InstallmentQuote{}, errors.New("payriff: installment amount must be positive")
+	}
+
+	rate, ok := t.Rate(bank, months)
+	if !ok || months <= 0 {
+		return InstallmentQuote{}, fmt.Errorf("%w: %s over %d months", ErrInstallmentNotOffered, bank, months)
+	}
+	if amount.Cmp(rate.MinAmount) < 0 {
+		return InstallmentQuote{}, fmt.Errorf("%w: %s requires at least %s", ErrInstallmentNotOffered, bank, rate.MinAmount)
+	}
+
+	surcharge := MinorUnits(int64(math.Round(float64(amount.Minor()) * rate.Percent / 100)))
+	total := amount.Add(surcharge)
+	payments := total.Split(months)
+
+	return InstallmentQuote{
+		Bank:        rate.Bank,
+		Months:      months,
+		Amount:      amount,
+		Surcharge:   surcharge,
+		Total:       total,
+		Monthly:     payments[0],
+		LastPayment: payments[months-1],
+	}, nil
+}
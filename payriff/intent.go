@@ -0,0 +1,266 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrIntentSettled is returned when paying an intent that already has a final outcome
+var ErrIntentSettled = errors.New("payriff: payment intent already settled")
+
+// IntentMethod is the Payriff mechanism used for a payment attempt
+type IntentMethod string
+
+const (
+	IntentHostedCheckout IntentMethod = "hosted_checkout"
+	IntentAutoPay        IntentMethod = "auto_pay"
+	IntentInvoice        IntentMethod = "invoice"
+)
+
+// IntentOutcome is the overall result of a payment intent
+type IntentOutcome string
+
+const (
+	IntentPending   IntentOutcome = "pending"
+	IntentSucceeded IntentOutcome = "succeeded"
+	IntentFailed    IntentOutcome = "failed"
+)
+
+// IntentAttempt records one try at fulfilling a payment intent
+type IntentAttempt struct {
+	Method IntentMethod
+	// OrderID is set once the order exists; invoice attempts get it when
+	// the invoice is paid
+	OrderID     OrderID
+	InvoiceUUID string
+	PaymentURL  string
+	Status      Status
+	Error       string
+	// Decline is set for declined AutoPay attempts and tells whether the
+	// charge may be retried
+	Decline *DeclineInfo
+	At      time.Time
+}
+
+// PaymentIntent represents "collect this amount from this customer",
+// independent of whether hosted checkout, AutoPay or an invoice fulfills it
+type PaymentIntent struct {
+	ID          string
+	Amount      Amount
+	Currency    Currency
+	Description string
+	CustomerID  string
+	Attempts    []IntentAttempt
+	Outcome     IntentOutcome
+}
+
+// NewPaymentIntent creates a pending payment intent
+func NewPaymentIntent(id string, amount Amount, currency Currency, description string) *PaymentIntent {
+	return &PaymentIntent{
+		ID:          id,
+		Amount:      amount,
+		Currency:    currency,
+		Description: description,
+		Outcome:     IntentPending,
+	}
+}
+
+// LastAttempt returns the most recent attempt, or nil
+func (pi *PaymentIntent) LastAttempt() *IntentAttempt {
+	if len(pi.Attempts) == 0 {
+		return nil
+	}
+	return &pi.Attempts[len(pi.Attempts)-1]
+}
+
+// Record applies a payment status reported for one of the intent's orders,
+// e.g. from a callback, and updates the outcome
+func (pi *PaymentIntent) Record(orderID OrderID, status Status) {
+	for i := range pi.Attempts {
+		if pi.Attempts[i].OrderID == orderID {
+			pi.Attempts[i].Status = status
+		}
+	}
+
+	switch {
+	case status.IsSuccessful():
+		pi.Outcome = IntentSucceeded
+	case status.IsFailed():
+		// A failed attempt only fails the intent when nothing newer is pending
+		if last := pi.LastAttempt(); last != nil && last.OrderID == orderID && pi.Outcome != IntentSucceeded {
+			pi.Outcome = IntentFailed
+		}
+	}
+}
+
+// PayWithCheckout fulfills the intent through a hosted checkout order. The
+// outcome stays pending until Record or RefreshIntent sees the result
+func (s *SDK) PayWithCheckout(ctx context.Context, pi *PaymentIntent) (*IntentAttempt, error) {
+	if pi.Outcome == IntentSucceeded {
+		return nil, ErrIntentSettled
+	}
+
+	resp, err := s.CreateOrderContext(ctx, CreateOrderRequest{
+		Amount:      pi.Amount,
+		Description: pi.Description,
+		Currency:    pi.Currency,
+	})
+	attempt := IntentAttempt{Method: IntentHostedCheckout, Status: StatusCreated, At: time.Now()}
+	if err == nil {
+		if rerr := resp.Err(); rerr != nil {
+			err = fmt.Errorf("order rejected: %w", rerr)
+		}
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		pi.Attempts = append(pi.Attempts, attempt)
+		return pi.LastAttempt(), err
+	}
+
+	attempt.OrderID = resp.Payload.OrderID
+	attempt.PaymentURL = resp.Payload.PaymentURL
+	pi.Attempts = append(pi.Attempts, attempt)
+	pi.Outcome = IntentPending
+	return pi.LastAttempt(), nil
+}
+
+// PayWithAutoPay fulfills the intent by charging a saved card
+func (s *SDK) PayWithAutoPay(ctx context.Context, pi *PaymentIntent, cardUUID CardUUID) (*IntentAttempt, error) {
+	if pi.Outcome == IntentSucceeded {
+		return nil, ErrIntentSettled
+	}
+
+	resp, err := s.ChargeSavedCard(ctx, AutoPayRequest{
+		CardUUID:    cardUUID,
+		Amount:      pi.Amount,
+		Description: pi.Description,
+		Currency:    pi.Currency,
+	})
+	attempt := IntentAttempt{Method: IntentAutoPay, At: time.Now()}
+	if err != nil {
+		// The charge may still have gone through, so the outcome stays as is
+		attempt.Error = err.Error()
+		pi.Attempts = append(pi.Attempts, attempt)
+		return pi.LastAttempt(), err
+	}
+
+	attempt.OrderID = resp.Payload.OrderID
+	attempt.Status = resp.Payload.PaymentStatus
+	attempt.Decline = resp.Payload.Decline()
+	if !resp.IsSuccessful() {
+		// Only a processor decline fails the attempt; other failures, such
+		// as invalid parameters, charged nothing and are returned as errors
+		if attempt.Decline == nil {
+			err := resp.Err()
+			attempt.Error = err.Error()
+			pi.Attempts = append(pi.Attempts, attempt)
+			return pi.LastAttempt(), err
+		}
+		attempt.Error = fmt.Sprintf("%s %s", resp.Code, resp.Message)
+		attempt.Status = StatusDeclined
+	} else if resp.Payload.ResponseCode != "" && !resp.Payload.Approved() {
+		attempt.Error = fmt.Sprintf("%s %s", resp.Payload.ResponseCode, resp.Payload.ResponseMessage)
+	}
+	pi.Attempts = append(pi.Attempts, attempt)
+	pi.Record(attempt.OrderID, attempt.Status)
+	return pi.LastAttempt(), nil
+}
+
+// PayWithInvoice fulfills the intent by sending the customer an invoice.
+// The amount, currency and description of req are taken from the intent.
+// The outcome stays pending until RefreshIntent sees the invoice paid,
+// expired or canceled
+func (s *SDK) PayWithInvoice(ctx context.Context, pi *PaymentIntent, req CreateInvoiceRequest) (*IntentAttempt, error) {
+	if pi.Outcome == IntentSucceeded {
+		return nil, ErrIntentSettled
+	}
+
+	req.Amount = pi.Amount
+	req.Currency = pi.Currency
+	req.Description = pi.Description
+	resp, err := s.CreateInvoice(ctx, req)
+	attempt := IntentAttempt{Method: IntentInvoice, Status: StatusCreated, At: time.Now()}
+	if err == nil {
+		if rerr := resp.Err(); rerr != nil {
+			err = fmt.Errorf("invoice rejected: %w", rerr)
+		}
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		pi.Attempts = append(pi.Attempts, attempt)
+		return pi.LastAttempt(), err
+	}
+
+	attempt.InvoiceUUID = resp.Payload.InvoiceUUID
+	attempt.PaymentURL = resp.Payload.PaymentURL
+	pi.Attempts = append(pi.Attempts, attempt)
+	pi.Outcome = IntentPending
+	return pi.LastAttempt(), nil
+}
+
+// RefreshIntent checks the latest attempt's order, or its invoice while
+// unpaid, with the gateway and updates the outcome. Failed or stale
+// lookups are returned as errors and change nothing
+func (s *SDK) RefreshIntent(ctx context.Context, pi *PaymentIntent) error {
+	last := pi.LastAttempt()
+	if last == nil {
+		return nil
+	}
+	if last.OrderID == "" && last.InvoiceUUID != "" {
+		return s.refreshInvoiceAttempt(ctx, pi, last)
+	}
+	if last.OrderID == "" {
+		return nil
+	}
+
+	info, err := s.GetOrderInfoContext(ctx, last.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to refresh intent %s: %w", pi.ID, err)
+	}
+	if err := info.Err(); err != nil {
+		return fmt.Errorf("failed to refresh intent %s: %w", pi.ID, err)
+	}
+	if info.Stale {
+		return fmt.Errorf("failed to refresh intent %s: %w", pi.ID, ErrGatewayUnavailable)
+	}
+	pi.Record(last.OrderID, info.Payload.PaymentStatus)
+	return nil
+}
+
+// refreshInvoiceAttempt follows an unpaid invoice attempt. A paid invoice
+// binds its order to the attempt, which is then refreshed like any other
+func (s *SDK) refreshInvoiceAttempt(ctx context.Context, pi *PaymentIntent, attempt *IntentAttempt) error {
+	inv, err := s.GetInvoice(ctx, attempt.InvoiceUUID)
+	if err != nil {
+		return fmt.Errorf("failed to refresh intent %s: %w", pi.ID, err)
+	}
+	if err := inv.Err(); err != nil {
+		return fmt.Errorf("failed to refresh intent %s: %w", pi.ID, err)
+	}
+	if inv.Stale {
+		return fmt.Errorf("failed to refresh intent %s: %w", pi.ID, ErrGatewayUnavailable)
+	}
+
+	switch inv.Payload.Status {
+	case InvoiceStatusPaid:
+		if inv.Payload.OrderID == nil || *inv.Payload.OrderID == "" {
+			attempt.Status = StatusApproved
+			pi.Outcome = IntentSucceeded
+			return nil
+		}
+		attempt.OrderID = OrderID(*inv.Payload.OrderID)
+		return s.RefreshIntent(ctx, pi)
+	case InvoiceStatusExpired:
+		attempt.Status = StatusExpired
+	case InvoiceStatusCanceled:
+		attempt.Status = StatusCanceled
+	default:
+		return nil
+	}
+	if pi.Outcome != IntentSucceeded {
+		pi.Outcome = IntentFailed
+	}
+	return nil
+}
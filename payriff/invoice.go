@@ -0,0 +1,115 @@
+package payriff
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// InvoiceDateLayout is the layout of invoice expiry dates
+const InvoiceDateLayout = "2006-01-02 15:04:05"
+
+// InvoiceStatus is the lifecycle state of an invoice
+type InvoiceStatus string
+
+const (
+	InvoiceStatusCreated  InvoiceStatus = "CREATED"
+	InvoiceStatusPaid     InvoiceStatus = "PAID"
+	InvoiceStatusExpired  InvoiceStatus = "EXPIRED"
+	InvoiceStatusCanceled InvoiceStatus = "CANCELED"
+)
+
+// CreateInvoiceRequest issues a payment invoice sent to a customer
+type CreateInvoiceRequest struct {
+	Amount      Amount   `json:"amount"`
+	Currency    Currency `json:"currencyType,omitempty"`
+	Description string   `json:"description"`
+	FullName    string   `json:"fullName,omitempty"`
+	Email       string   `json:"email,omitempty"`
+	PhoneNumber string   `json:"phoneNumber,omitempty"`
+	// ExpireDate is the due date in InvoiceDateLayout, see InvoiceDueDate
+	ExpireDate  string   `json:"expireDate,omitempty"`
+	Language    Language `json:"languageType,omitempty"`
+	CallbackURL string   `json:"callbackUrl,omitempty"`
+	// SendSMS has the gateway text its own payment link. To send a link
+	// shortened by Config.URLShortener, leave it unset and text
+	// InvoicePayload.PaymentURL yourself
+	SendSMS      bool `json:"sendSms"`
+	SendEmail    bool `json:"sendEmail"`
+	SendWhatsApp bool `json:"sendWhatsapp"`
+}
+
+// InvoiceDueDate formats t for CreateInvoiceRequest.ExpireDate
+func InvoiceDueDate(t time.Time) string {
+	return t.Format(InvoiceDateLayout)
+}
+
+// InvoicePayload describes an invoice
+type InvoicePayload struct {
+	InvoiceUUID  string        `json:"invoiceUuid"`
+	PaymentURL   string        `json:"paymentUrl"`
+	Amount       float64       `json:"amount"`
+	CurrencyType Currency      `json:"currencyType"`
+	Description  string        `json:"description"`
+	FullName     string        `json:"fullName"`
+	Email        string        `json:"email"`
+	PhoneNumber  string        `json:"phoneNumber"`
+	ExpireDate   string        `json:"expireDate"`
+	Status       InvoiceStatus `json:"invoiceStatus"`
+	// OrderID is set once the invoice has been paid
+	OrderID     *string `json:"orderId,omitempty"`
+	CreatedDate string  `json:"createdDate"`
+}
+
+// DueDate parses ExpireDate
+func (p InvoicePayload) DueDate() (time.Time, error) {
+	t, err := time.ParseInLocation(InvoiceDateLayout, p.ExpireDate, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse invoice due date: %w", err)
+	}
+	return t, nil
+}
+
+// CreateInvoice issues a payment invoice
+func (s *SDK) CreateInvoice(ctx context.Context, req CreateInvoiceRequest) (*ApiResponse[InvoicePayload], error) {
+	// Apply defaults if values are not provided
+	if req.Language == "" {
+		req.Language = s.defaultLanguage
+	}
+	if req.Currency == "" {
+		req.Currency = s.defaultCurrency
+	}
+	if req.CallbackURL == "" {
+		req.CallbackURL = s.defaultCallbackURL
+	}
+
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.makeRequest(ctx, "/invoices", http.MethodPost, ScopeSecret, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := decodeResponse[InvoicePayload](s, "POST /invoices", resp)
+	if err == nil {
+		result.Payload.PaymentURL = s.shortenURL(ctx, result.Payload.PaymentURL)
+	}
+	return result, err
+}
+
+// GetInvoice retrieves an invoice by UUID
+func (s *SDK) GetInvoice(ctx context.Context, invoiceUUID string) (*ApiResponse[InvoicePayload], error) {
+	resp, err := s.makeRequest(ctx, fmt.Sprintf("/invoices/%s", invoiceUUID), http.MethodGet, ScopePublic, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := decodeResponse[InvoicePayload](s, "GET /invoices/{invoiceUuid}", resp)
+	if err == nil {
+		result.Payload.PaymentURL = s.shortenURL(ctx, result.Payload.PaymentURL)
+	}
+	return result, err
+}
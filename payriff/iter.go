@@ -0,0 +1,94 @@
+package payriff
+
+import (
+	"context"
+	"iter"
+	"sort"
+	"time"
+)
+
+// Orders lazily fetches each order yielded by orderIDs. Iteration stops
+// when the caller breaks or ctx is done; a failed fetch or a failure
+// response yields an OrderInfo holding only the order ID together with the
+// error, so the caller decides whether to go on
+func (s *SDK) Orders(ctx context.Context, orderIDs iter.Seq[OrderID]) iter.Seq2[OrderInfo, error] {
+	return func(yield func(OrderInfo, error) bool) {
+		for id := range orderIDs {
+			if err := ctx.Err(); err != nil {
+				yield(OrderInfo{}, err)
+				return
+			}
+
+			resp, err := s.GetOrderInfoContext(ctx, id)
+			if err != nil {
+				if !yield(OrderInfo{OrderID: id}, err) {
+					return
+				}
+				continue
+			}
+			if err := resp.Err(); err != nil {
+				if !yield(OrderInfo{OrderID: id}, err) {
+					return
+				}
+				continue
+			}
+			if !yield(resp.Payload, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Transactions lazily yields the transactions of each order yielded by
+// orderIDs, fetching one order at a time
+func (s *SDK) Transactions(ctx context.Context, orderIDs iter.Seq[OrderID]) iter.Seq2[Transaction, error] {
+	return func(yield func(Transaction, error) bool) {
+		for order, err := range s.Orders(ctx, orderIDs) {
+			if err != nil {
+				if !yield(Transaction{}, err) {
+					return
+				}
+				continue
+			}
+			for _, tx := range order.Transactions {
+				if !yield(tx, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// All yields parked dead letters oldest first. It snapshots only the keys
+// and parking times and looks each letter up as it is yielded, so bodies
+// are not copied up front and letters removed meanwhile are skipped
+func (q *MemoryDeadLetterQueue) All() iter.Seq[DeadLetter] {
+	return func(yield func(DeadLetter) bool) {
+		type entry struct {
+			key      string
+			parkedAt time.Time
+		}
+
+		q.mu.Lock()
+		entries := make([]entry, 0, len(q.letters))
+		for key, dl := range q.letters {
+			entries = append(entries, entry{key: key, parkedAt: dl.ParkedAt})
+		}
+		q.mu.Unlock()
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].parkedAt.Before(entries[j].parkedAt)
+		})
+
+		for _, e := range entries {
+			q.mu.Lock()
+			dl, ok := q.letters[e.key]
+			q.mu.Unlock()
+			if !ok {
+				continue
+			}
+			if !yield(dl) {
+				return
+			}
+		}
+	}
+}
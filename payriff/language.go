@@ -0,0 +1,72 @@
+package payriff
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// languageFallbacks maps browser languages the payment page does not
+// support to the closest one a shopper is likely to read
+var languageFallbacks = map[string]Language{
+	"az": LanguageAZ,
+	"tr": LanguageAZ,
+	"en": LanguageEN,
+	"ru": LanguageRU,
+	"be": LanguageRU,
+	"uk": LanguageRU,
+	"kk": LanguageRU,
+	"ky": LanguageRU,
+	"tg": LanguageRU,
+}
+
+// ParseAcceptLanguage returns the payment page language closest to an
+// Accept-Language header, e.g. "ru-RU,ru;q=0.9,en;q=0.8". Languages are
+// tried by preference; Turkish falls back to AZ and languages commonly
+// read alongside Russian to RU. Other languages get EN, and fallback is
+// returned when the header names none
+func ParseAcceptLanguage(header string, fallback Language) Language {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{tag: tag, q: q})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		primary, _, _ := strings.Cut(strings.ReplaceAll(c.tag, "_", "-"), "-")
+		if language, ok := languageFallbacks[primary]; ok {
+			return language
+		}
+	}
+	if len(candidates) > 0 {
+		return LanguageEN
+	}
+	return fallback
+}
+
+// RequestLanguage returns the payment page language for a shopper's
+// request, from its Accept-Language header, defaulting to
+// Config.DefaultLanguage
+func (s *SDK) RequestLanguage(r *http.Request) Language {
+	return ParseAcceptLanguage(r.Header.Get("Accept-Language"), s.defaultLanguage)
+}
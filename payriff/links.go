@@ -0,0 +1,113 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrOrderAlreadyPaid is returned when regenerating the link of a paid order
+	ErrOrderAlreadyPaid = errors.New("payriff: order already paid")
+	// ErrOrderStillPayable is returned when regenerating the link of an
+	// order whose own link can still be paid
+	ErrOrderStillPayable = errors.New("payriff: order can still be paid")
+)
+
+// SupersessionStore records which order replaced another
+type SupersessionStore interface {
+	Supersede(ctx context.Context, oldOrderID, newOrderID OrderID) error
+	// Successor returns the order that replaced orderID, if any
+	Successor(ctx context.Context, orderID OrderID) (OrderID, bool, error)
+}
+
+// PaymentLinks regenerates payment links for orders whose hosted page
+// expired. The new order supersedes the old one, and Current follows the
+// chain, so references to the original order keep resolving
+type PaymentLinks struct {
+	SDK   *SDK
+	Store SupersessionStore
+}
+
+// RegeneratePaymentURL creates a replacement for the latest order in
+// orderID's chain and records the supersession. Only expired, canceled or
+// declined orders are replaced, so the shopper never holds two payable
+// links: paid orders fail with ErrOrderAlreadyPaid, and live ones with
+// ErrOrderStillPayable until they are canceled or expire
+func (l *PaymentLinks) RegeneratePaymentURL(ctx context.Context, orderID OrderID) (*ApiResponse[OrderPayload], error) {
+	current, err := l.Current(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := l.SDK.GetOrderInfoContext(ctx, current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order %s: %w", current, err)
+	}
+	if err := info.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load order %s: %w", current, err)
+	}
+	switch status := info.Payload.PaymentStatus; {
+	case status.IsPaid():
+		return nil, fmt.Errorf("%w: %s", ErrOrderAlreadyPaid, current)
+	case !status.IsFailed():
+		return nil, fmt.Errorf("%w: %s is %s", ErrOrderStillPayable, current, status)
+	}
+
+	resp, err := l.SDK.CloneOrder(ctx, current, CreateOrderRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccessful() {
+		return resp, nil
+	}
+
+	if err := l.Store.Supersede(ctx, current, resp.Payload.OrderID); err != nil {
+		return nil, fmt.Errorf("failed to record supersession of order %s: %w", current, err)
+	}
+	return resp, nil
+}
+
+// Current returns the latest order in orderID's supersession chain
+func (l *PaymentLinks) Current(ctx context.Context, orderID OrderID) (OrderID, error) {
+	seen := map[OrderID]bool{orderID: true}
+	for {
+		next, ok, err := l.Store.Successor(ctx, orderID)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve successor of order %s: %w", orderID, err)
+		}
+		if !ok || seen[next] {
+			return orderID, nil
+		}
+		seen[next] = true
+		orderID = next
+	}
+}
+
+// MemorySupersessionStore is an in-process SupersessionStore
+type MemorySupersessionStore struct {
+	mu         sync.Mutex
+	successors map[OrderID]OrderID
+}
+
+// Supersede implements SupersessionStore
+func (m *MemorySupersessionStore) Supersede(ctx context.Context, oldOrderID, newOrderID OrderID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.successors == nil {
+		m.successors = make(map[OrderID]OrderID)
+	}
+	m.successors[oldOrderID] = newOrderID
+	return nil
+}
+
+// Successor implements SupersessionStore
+func (m *MemorySupersessionStore) Successor(ctx context.Context, orderID OrderID) (OrderID, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next, ok := m.successors[orderID]
+	return next, ok, nil
+}
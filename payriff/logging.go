@@ -0,0 +1,129 @@
+package payriff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Redacted replaces sensitive values in logged bodies
+const Redacted = "[REDACTED]"
+
+// sensitiveFields are JSON keys whose values are never logged, compared
+// case-insensitively
+var sensitiveFields = map[string]bool{
+	"pan":            true,
+	"maskedpan":      true,
+	"cardnumber":     true,
+	"cardholdername": true,
+	"cvv":            true,
+	"cvc":            true,
+	"expirydate":     true,
+	"secretkey":      true,
+	"authorization":  true,
+	"access_token":   true,
+	"client_secret":  true,
+	"password":       true,
+	"fullname":       true,
+	"email":          true,
+	"phone":          true,
+	"phonenumber":    true,
+}
+
+// panPattern matches full or masked card numbers inside other strings
+var panPattern = regexp.MustCompile(`\b[0-9]{4,6}[0-9*xX]{6,9}[0-9]{4}\b`)
+
+// Redact returns body with card numbers, card holder names, secrets and
+// customer contact details replaced by Redacted, keeping logs PCI-safe. Bodies that are not JSON
+// only have card numbers replaced
+func Redact(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return panPattern.ReplaceAll(body, []byte(Redacted))
+	}
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return []byte(Redacted)
+	}
+	return out
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			if sensitiveFields[strings.ToLower(k)] {
+				if val != nil {
+					v[k] = Redacted
+				}
+				continue
+			}
+			v[k] = redactValue(val)
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	case string:
+		return panPattern.ReplaceAllString(v, Redacted)
+	}
+	return v
+}
+
+// logCall logs one attempt of an API call. The Authorization header is
+// never logged
+func (s *SDK) logCall(ctx context.Context, method, endpoint string, payload []byte, status int, data []byte, result *Response, latency time.Duration, err error) {
+	if s.logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("endpoint", endpoint),
+		slog.Duration("latency", latency),
+	}
+	if status != 0 {
+		attrs = append(attrs, slog.Int("status", status))
+	}
+	if result != nil {
+		attrs = append(attrs, slog.String("code", string(result.Code)))
+		if result.ResponseID != "" {
+			attrs = append(attrs, slog.String("responseId", result.ResponseID))
+		}
+	}
+	if o, ok := OrderFrom(ctx); ok {
+		attrs = append(attrs, o.Attrs()...)
+	}
+	if key := idempotencyKey(ctx); key != "" {
+		attrs = append(attrs, slog.String("idempotencyKey", key))
+	}
+	if s.logger.Enabled(ctx, slog.LevelDebug) {
+		if len(payload) > 0 {
+			attrs = append(attrs, slog.String("request", string(Redact(payload))))
+		}
+		if len(data) > 0 {
+			attrs = append(attrs, slog.String("response", string(Redact(data))))
+		}
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case err != nil:
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", err.Error()))
+	case result != nil && !result.IsSuccessful():
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("message", result.Message))
+	}
+	s.logger.LogAttrs(ctx, level, "payriff api call", attrs...)
+}
@@ -0,0 +1,124 @@
+package payriff
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrGatewayMaintenance matches errors for calls rejected while the
+// gateway is down for maintenance
+var ErrGatewayMaintenance = errors.New("payriff: gateway under maintenance")
+
+// MaintenanceKeywords are lower-case phrases that mark a gateway response
+// as a maintenance notice, in English, Azerbaijani and Russian
+var MaintenanceKeywords = []string{
+	"maintenance",
+	"texniki iş",
+	"texniki xidmət",
+	"технические работы",
+	"техническое обслуживание",
+}
+
+// MaintenanceError reports a maintenance notice from the gateway. It
+// matches ErrGatewayMaintenance with errors.Is and unwraps to the
+// *HTTPError or *APIError that carried the notice
+type MaintenanceError struct {
+	// RetryAfter is the gateway's estimate of when to try again, zero when
+	// it gave none
+	RetryAfter time.Duration
+	// Notice is the text of the notice, if any
+	Notice string
+	Err    error
+}
+
+func (e *MaintenanceError) Error() string {
+	msg := ErrGatewayMaintenance.Error()
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", retry after %s", e.RetryAfter)
+	}
+	if e.Notice != "" {
+		msg += ": " + e.Notice
+	}
+	return msg
+}
+
+// Is reports whether target is ErrGatewayMaintenance
+func (e *MaintenanceError) Is(target error) bool {
+	return target == ErrGatewayMaintenance
+}
+
+func (e *MaintenanceError) Unwrap() error {
+	return e.Err
+}
+
+// htmlTag matches markup stripped from notices
+var htmlTag = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]+>`)
+
+// maintenanceNotice returns the text of body when it reads as a
+// maintenance notice
+func maintenanceNotice(body string) (string, bool) {
+	text := strings.Join(strings.Fields(htmlTag.ReplaceAllString(body, " ")), " ")
+	lower := strings.ToLower(text)
+	for _, keyword := range MaintenanceKeywords {
+		if strings.Contains(lower, keyword) {
+			if runes := []rune(text); len(runes) > 200 {
+				text = string(runes[:200]) + "…"
+			}
+			return text, true
+		}
+	}
+	return "", false
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// maintenanceFromHTTP turns an *HTTPError carrying a maintenance notice,
+// or a 503 with Retry-After, into a *MaintenanceError
+func maintenanceFromHTTP(err error) error {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return err
+	}
+
+	after := retryAfter(httpErr.Header, time.Now())
+	notice, ok := maintenanceNotice(string(httpErr.Body))
+	if !ok && !(httpErr.StatusCode == http.StatusServiceUnavailable && after > 0) {
+		return err
+	}
+	return &MaintenanceError{RetryAfter: after, Notice: notice, Err: err}
+}
+
+// maintenanceFromResponse returns a *MaintenanceError for a non-success
+// envelope whose message is a maintenance notice
+func (s *SDK) maintenanceFromResponse(resp *Response) error {
+	if resp.IsSuccessful() {
+		return nil
+	}
+	text := resp.Message
+	if resp.InternalMessage != nil {
+		text += " " + *resp.InternalMessage
+	}
+	notice, ok := maintenanceNotice(text)
+	if !ok {
+		return nil
+	}
+	return &MaintenanceError{Notice: notice, Err: newAPIError(resp)}
+}
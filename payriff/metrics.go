@@ -0,0 +1,76 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// CallMetric describes one attempt of an API call. Its labels have bounded
+// cardinality, so they can be used directly as metric labels
+type CallMetric struct {
+	// Endpoint is the path pattern with method, e.g. GET /orders/{orderId}
+	Endpoint string
+	// Operation is the SDK method calling the endpoint, empty when the
+	// endpoint is not in the registry
+	Operation string
+	// Status is the HTTP status, 0 when no response was received
+	Status int
+	// Code is the result code of the envelope, empty when none was decoded
+	Code    ResultCode
+	Latency time.Duration
+	Err     error
+	// Timeout is set when the attempt failed on a deadline
+	Timeout bool
+}
+
+// Failed reports whether the attempt failed or the gateway returned a
+// non-success result code
+func (m CallMetric) Failed() bool {
+	return m.Err != nil || (m.Code != "" && m.Code != ResultCodeSuccess)
+}
+
+// MetricsCollector receives an observation for every attempt of an API call,
+// for exporting request counts, failures by result code and latency
+// histograms to a metrics system
+type MetricsCollector interface {
+	ObserveCall(ctx context.Context, m CallMetric)
+}
+
+// MetricsCollectorFunc adapts a function to the MetricsCollector interface
+type MetricsCollectorFunc func(ctx context.Context, m CallMetric)
+
+// ObserveCall calls f(ctx, m)
+func (f MetricsCollectorFunc) ObserveCall(ctx context.Context, m CallMetric) {
+	f(ctx, m)
+}
+
+// observeCall reports one attempt to the configured collector
+func (s *SDK) observeCall(ctx context.Context, method, endpoint string, status int, result *Response, latency time.Duration, err error) {
+	if s.metrics == nil {
+		return
+	}
+
+	path, _, _ := strings.Cut(endpoint, "?")
+	m := CallMetric{
+		Endpoint: method + " " + path,
+		Status:   status,
+		Latency:  latency,
+		Err:      err,
+	}
+	if info, ok := LookupEndpoint(method, endpoint); ok {
+		m.Endpoint = info.Pattern()
+		m.Operation = info.Name
+	}
+	if result != nil {
+		m.Code = result.Code
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		m.Timeout = true
+	}
+
+	s.runHook(func() { s.metrics.ObserveCall(ctx, m) })
+}
@@ -0,0 +1,204 @@
+package payriff
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidAmount is returned when an amount cannot be parsed
+var ErrInvalidAmount = errors.New("payriff: invalid amount")
+
+// minorDigits is the number of decimal places of every supported currency
+const minorDigits = 2
+
+// minorPerMajor is the number of minor units in a major unit
+const minorPerMajor = 100
+
+// Amount is a monetary amount in integer minor units (qəpik, cents), so
+// sums and refunds are exact. It is sent to the gateway as a decimal
+// number with two decimal places. The zero value is zero
+type Amount struct {
+	minor int64
+}
+
+// MinorUnits returns an amount of n minor units, e.g. MinorUnits(1050) is 10.50
+func MinorUnits(n int64) Amount {
+	return Amount{minor: n}
+}
+
+// AmountOf converts a decimal amount such as 10.5, rounding to the nearest
+// minor unit. Use it for literals and for amounts reported by the gateway
+func AmountOf(major float64) Amount {
+	return Amount{minor: int64(math.Round(major * minorPerMajor))}
+}
+
+// ParseAmount parses a decimal amount such as "10.50" or "-3" exactly.
+// More than two decimal places are rejected
+func ParseAmount(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(s, "-")
+
+	whole, frac, _ := strings.Cut(digits, ".")
+	if whole == "" || len(frac) > minorDigits || !isDigits(whole) || !isDigits(frac) {
+		return Amount{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	frac += strings.Repeat("0", minorDigits-len(frac))
+
+	major, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || major > math.MaxInt64/minorPerMajor-1 {
+		return Amount{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	minor, _ := strconv.ParseInt(frac, 10, 64)
+
+	a := Amount{minor: major*minorPerMajor + minor}
+	if neg {
+		a.minor = -a.minor
+	}
+	return a, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Minor returns the amount in minor units
+func (a Amount) Minor() int64 {
+	return a.minor
+}
+
+// Float64 returns the amount in major units, for display and interop
+func (a Amount) Float64() float64 {
+	return float64(a.minor) / minorPerMajor
+}
+
+// Add returns a+b
+func (a Amount) Add(b Amount) Amount {
+	return Amount{minor: a.minor + b.minor}
+}
+
+// Sub returns a-b
+func (a Amount) Sub(b Amount) Amount {
+	return Amount{minor: a.minor - b.minor}
+}
+
+// Mul returns a multiplied by n, e.g. a unit price by a quantity
+func (a Amount) Mul(n int64) Amount {
+	return Amount{minor: a.minor * n}
+}
+
+// Split divides the amount into n parts that add up to a exactly, giving
+// the remainder to the last part
+func (a Amount) Split(n int) []Amount {
+	if n <= 0 {
+		return nil
+	}
+	parts := make([]Amount, n)
+	each := a.minor / int64(n)
+	for i := range parts {
+		parts[i] = Amount{minor: each}
+	}
+	parts[n-1].minor += a.minor - each*int64(n)
+	return parts
+}
+
+// Cmp returns -1, 0 or +1 depending on whether a is less than, equal to or
+// greater than b
+func (a Amount) Cmp(b Amount) int {
+	switch {
+	case a.minor < b.minor:
+		return -1
+	case a.minor > b.minor:
+		return 1
+	}
+	return 0
+}
+
+// IsZero reports whether the amount is zero
+func (a Amount) IsZero() bool {
+	return a.minor == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (a Amount) IsPositive() bool {
+	return a.minor > 0
+}
+
+// String formats the amount with two decimal places, e.g. "10.50"
+func (a Amount) String() string {
+	sign, minor := "", a.minor
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/minorPerMajor, minor%minorPerMajor)
+}
+
+// MarshalJSON encodes the amount as a decimal number
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON decodes a decimal number or string, rounding numbers with
+// more than two decimal places to the nearest minor unit
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		return nil
+	}
+	if parsed, err := ParseAmount(s); err == nil {
+		*a = parsed
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAmount, data)
+	}
+	*a = AmountOf(f)
+	return nil
+}
+
+// Money is an amount in a currency
+type Money struct {
+	Amount   Amount
+	Currency Currency
+}
+
+// ParseMoney parses money such as "10.50 AZN"
+func ParseMoney(s string) (Money, error) {
+	amount, currency, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q has no currency", ErrInvalidAmount, s)
+	}
+	a, err := ParseAmount(amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: a, Currency: Currency(strings.ToUpper(strings.TrimSpace(currency)))}, nil
+}
+
+// String formats the money as "10.50 AZN"
+func (m Money) String() string {
+	return m.Amount.String() + " " + string(m.Currency)
+}
+
+// Format formats the money with the currency symbol in its usual place,
+// e.g. "10.50 ₼", "$10.50" or "€10.50". Other currencies fall back to String
+func (m Money) Format() string {
+	switch m.Currency {
+	case CurrencyAZN:
+		return m.Amount.String() + " ₼"
+	case CurrencyUSD:
+		return "$" + m.Amount.String()
+	case CurrencyEUR:
+		return "€" + m.Amount.String()
+	}
+	return m.String()
+}
@@ -0,0 +1,54 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// MPAYTopupRequest tops up an MPAY wallet identified by phone number
+type MPAYTopupRequest struct {
+	// PhoneNumber is the wallet's phone number in international form, e.g.
+	// 994501234567. A leading + and spaces are removed
+	PhoneNumber string   `json:"phoneNumber"`
+	Amount      Amount   `json:"amount"`
+	Currency    Currency `json:"currency,omitempty"`
+	Description string   `json:"description"`
+}
+
+// MPAYTopupPayload is the result of an MPAY wallet top-up
+type MPAYTopupPayload struct {
+	TransactionID string   `json:"transactionId"`
+	PhoneNumber   string   `json:"phoneNumber"`
+	Amount        float64  `json:"amount"`
+	CurrencyType  Currency `json:"currencyType"`
+	Status        Status   `json:"status"`
+}
+
+// TopupMPAY tops up an MPAY wallet
+func (s *SDK) TopupMPAY(ctx context.Context, req MPAYTopupRequest) (*ApiResponse[MPAYTopupPayload], error) {
+	req.PhoneNumber = strings.NewReplacer("+", "", " ", "", "-", "").Replace(req.PhoneNumber)
+	if req.PhoneNumber == "" {
+		return nil, errors.New("payriff: MPAY top-up requires a phone number")
+	}
+	if !req.Amount.IsPositive() {
+		return nil, errors.New("payriff: MPAY top-up amount must be positive")
+	}
+
+	// Apply defaults if values are not provided
+	if req.Currency == "" {
+		req.Currency = s.defaultCurrency
+	}
+
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.makeRequest(ctx, "/mpay/topup", http.MethodPost, ScopeSecret, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse[MPAYTopupPayload](s, "POST /mpay/topup", resp)
+}
@@ -0,0 +1,152 @@
+package payriff
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"strings"
+)
+
+// NotificationKind identifies the type of a notification
+type NotificationKind string
+
+const (
+	NotificationCardExpiring      NotificationKind = "card.expiring"
+	NotificationReport            NotificationKind = "report"
+	NotificationCommissionDispute NotificationKind = "commission.dispute"
+)
+
+// Notification is a message delivered through a Notifier
+type Notification struct {
+	Kind        NotificationKind
+	Subject     string
+	Body        string
+	Data        map[string]any
+	Attachments []Attachment
+}
+
+// Attachment is a file sent along with a notification
+type Attachment struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+// Notifier delivers notifications to an external channel such as email,
+// chat or an internal HTTP endpoint
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface
+type NotifierFunc func(ctx context.Context, n Notification) error
+
+// Notify calls f(ctx, n)
+func (f NotifierFunc) Notify(ctx context.Context, n Notification) error {
+	return f(ctx, n)
+}
+
+// HTTPNotifier posts notifications as JSON to an endpoint. Attachment
+// contents are base64 encoded
+type HTTPNotifier struct {
+	URL    string
+	Header http.Header
+	// Client defaults to a client with DefaultTimeout
+	Client *http.Client
+}
+
+// Notify implements Notifier
+func (h *HTTPNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range h.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := h.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("payriff: notification endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// EmailNotifier sends notifications as email over SMTP, with attachments
+// as MIME parts
+type EmailNotifier struct {
+	// Addr is the SMTP server host:port
+	Addr string
+	// Auth is optional, e.g. smtp.PlainAuth
+	Auth smtp.Auth
+	From string
+	To   []string
+}
+
+// Notify implements Notifier. The context is not observed by net/smtp
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := smtp.SendMail(e.Addr, e.Auth, e.From, e.To, e.message(n)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// message builds a multipart/mixed email holding the body and attachments
+func (e *EmailNotifier) message(n Notification) []byte {
+	const boundary = "payriff-notification-boundary"
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", e.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", boundary)
+
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(n.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	for _, a := range n.Attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		fmt.Fprintf(&b, "Content-Type: %s\r\n", contentType)
+		b.WriteString("Content-Transfer-Encoding: base64\r\n")
+		fmt.Fprintf(&b, "Content-Disposition: attachment; filename=%q\r\n\r\n", a.Name)
+
+		encoded := base64.StdEncoding.EncodeToString(a.Content)
+		for len(encoded) > 76 {
+			b.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		b.WriteString(encoded + "\r\n")
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.Bytes()
+}
@@ -0,0 +1,18 @@
+package payriff
+
+import "context"
+
+type operatorCtx struct{}
+
+// WithOperator returns a context that attributes SDK calls made with it to
+// operator, such as a back-office user ID. The operator is recorded in
+// audit records and saga journal entries
+func WithOperator(ctx context.Context, operator string) context.Context {
+	return context.WithValue(ctx, operatorCtx{}, operator)
+}
+
+// OperatorFrom returns the operator set with WithOperator
+func OperatorFrom(ctx context.Context) string {
+	operator, _ := ctx.Value(operatorCtx{}).(string)
+	return operator
+}
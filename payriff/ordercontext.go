@@ -0,0 +1,86 @@
+package payriff
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type orderCtx struct{}
+
+// OrderScope identifies the payment a context belongs to
+type OrderScope struct {
+	OrderID OrderID
+	Money   Money
+}
+
+// orderScope is the context value behind WithOrder. CreateOrderContext
+// fills in the order ID once the gateway assigns one, so a scope can be
+// opened before the order exists
+type orderScope struct {
+	mu    sync.Mutex
+	scope OrderScope
+}
+
+// WithOrder returns a context that ties SDK calls made with it to one
+// payment. Log records of those calls, and of callbacks for the order, carry
+// orderId, amount and currency. orderID may be empty when the context is
+// used to create the order
+func WithOrder(ctx context.Context, orderID OrderID, money Money) context.Context {
+	return context.WithValue(ctx, orderCtx{}, &orderScope{scope: OrderScope{OrderID: orderID, Money: money}})
+}
+
+// OrderFrom returns the scope set with WithOrder
+func OrderFrom(ctx context.Context) (OrderScope, bool) {
+	o, ok := ctx.Value(orderCtx{}).(*orderScope)
+	if !ok {
+		return OrderScope{}, false
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.scope, true
+}
+
+// bindOrder records the order created within a scope that has no order ID yet
+func bindOrder(ctx context.Context, orderID OrderID) {
+	o, ok := ctx.Value(orderCtx{}).(*orderScope)
+	if !ok {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.scope.OrderID == "" {
+		o.scope.OrderID = orderID
+	}
+}
+
+// Attrs returns the scope as log attributes, leaving out unset fields
+func (o OrderScope) Attrs() []slog.Attr {
+	var attrs []slog.Attr
+	if o.OrderID != "" {
+		attrs = append(attrs, slog.String("orderId", string(o.OrderID)))
+	}
+	if !o.Money.Amount.IsZero() {
+		attrs = append(attrs, slog.String("amount", o.Money.Amount.String()))
+	}
+	if o.Money.Currency != "" {
+		attrs = append(attrs, slog.String("currency", string(o.Money.Currency)))
+	}
+	return attrs
+}
+
+// Logger returns the configured logger, or slog.Default when there is none,
+// with the attributes of ctx's order scope attached, for application log
+// lines that should correlate with the SDK's own
+func (s *SDK) Logger(ctx context.Context) *slog.Logger {
+	logger := s.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if o, ok := OrderFrom(ctx); ok {
+		for _, attr := range o.Attrs() {
+			logger = logger.With(attr)
+		}
+	}
+	return logger
+}
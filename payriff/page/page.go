@@ -0,0 +1,105 @@
+// Package page renders small shopper-facing HTML pages for Payriff
+// checkouts: a "redirecting to payment" page and a payment result page.
+package page
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+
+	"github.com/kerimovok/payriff-sdk-go/payriff"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+// Options customize the look of rendered pages
+type Options struct {
+	// Brand is the merchant name shown in the header
+	Brand string
+	// LogoURL is an optional logo image shown above the brand
+	LogoURL string
+	// AccentColor is a CSS color for buttons and headings, defaults to #1a56db
+	AccentColor string
+	// Language selects the page text, defaults to payriff.LanguageAZ
+	Language payriff.Language
+	// ReturnURL is the "back to shop" link on the result page
+	ReturnURL string
+}
+
+// Result is the outcome shown on the payment result page
+type Result struct {
+	OrderID  payriff.OrderID
+	Status   payriff.Status
+	Amount   float64
+	Currency payriff.Currency
+}
+
+// ResultFromOrder builds a Result from order information
+func ResultFromOrder(info payriff.OrderInfo) Result {
+	return Result{
+		OrderID:  info.OrderID,
+		Status:   info.PaymentStatus,
+		Amount:   info.Amount,
+		Currency: info.CurrencyType,
+	}
+}
+
+type pageData struct {
+	Options
+	Text       texts
+	PaymentURL string
+	Result     Result
+	Outcome    string
+	Message    string
+}
+
+// RenderRedirect writes a page that forwards the shopper to the hosted
+// payment page, with a manual link for browsers that block the redirect
+func RenderRedirect(w io.Writer, order payriff.OrderPayload, opts Options) error {
+	data := newPageData(opts)
+	data.PaymentURL = order.PaymentURL
+	if err := templates.ExecuteTemplate(w, "redirect.html", data); err != nil {
+		return fmt.Errorf("failed to render redirect page: %w", err)
+	}
+	return nil
+}
+
+// RenderResult writes a page describing the payment outcome
+func RenderResult(w io.Writer, result Result, opts Options) error {
+	data := newPageData(opts)
+	data.Result = result
+	data.Outcome, data.Message = data.Text.outcome(result.Status)
+	if err := templates.ExecuteTemplate(w, "result.html", data); err != nil {
+		return fmt.Errorf("failed to render result page: %w", err)
+	}
+	return nil
+}
+
+// ServeRedirect renders the redirect page as an HTTP response
+func ServeRedirect(w http.ResponseWriter, order payriff.OrderPayload, opts Options) error {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	return RenderRedirect(w, order, opts)
+}
+
+// ServeResult renders the result page as an HTTP response
+func ServeResult(w http.ResponseWriter, result Result, opts Options) error {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	return RenderResult(w, result, opts)
+}
+
+func newPageData(opts Options) pageData {
+	if opts.AccentColor == "" {
+		opts.AccentColor = "#1a56db"
+	}
+	if opts.Language == "" {
+		opts.Language = payriff.LanguageAZ
+	}
+	return pageData{Options: opts, Text: textsFor(opts.Language)}
+}
@@ -0,0 +1,89 @@
+package page
+
+import "github.com/kerimovok/payriff-sdk-go/payriff"
+
+type texts struct {
+	Redirecting   string
+	RedirectHint  string
+	ContinueLabel string
+	OrderLabel    string
+	AmountLabel   string
+	BackLabel     string
+
+	Approved, ApprovedMsg string
+	Pending, PendingMsg   string
+	Failed, FailedMsg     string
+	Refunded, RefundedMsg string
+}
+
+var translations = map[payriff.Language]texts{
+	payriff.LanguageAZ: {
+		Redirecting:   "Ödəniş səhifəsinə yönləndirilirsiniz",
+		RedirectHint:  "Yönləndirmə baş vermirsə, aşağıdakı düyməni basın.",
+		ContinueLabel: "Ödənişə keçin",
+		OrderLabel:    "Sifariş",
+		AmountLabel:   "Məbləğ",
+		BackLabel:     "Mağazaya qayıdın",
+		Approved:      "Ödəniş uğurla tamamlandı",
+		ApprovedMsg:   "Təşəkkür edirik! Ödənişiniz qəbul edildi.",
+		Pending:       "Ödəniş gözlənilir",
+		PendingMsg:    "Ödənişiniz hələ tamamlanmayıb. Bir neçə dəqiqədən sonra yenidən yoxlayın.",
+		Failed:        "Ödəniş uğursuz oldu",
+		FailedMsg:     "Ödəniş həyata keçirilmədi. Başqa kartla yenidən cəhd edin.",
+		Refunded:      "Ödəniş geri qaytarıldı",
+		RefundedMsg:   "Bu sifariş üzrə vəsait kartınıza qaytarıldı.",
+	},
+	payriff.LanguageEN: {
+		Redirecting:   "Redirecting you to the payment page",
+		RedirectHint:  "If nothing happens, press the button below.",
+		ContinueLabel: "Continue to payment",
+		OrderLabel:    "Order",
+		AmountLabel:   "Amount",
+		BackLabel:     "Back to shop",
+		Approved:      "Payment successful",
+		ApprovedMsg:   "Thank you! Your payment has been received.",
+		Pending:       "Payment pending",
+		PendingMsg:    "Your payment is not complete yet. Please check again in a few minutes.",
+		Failed:        "Payment failed",
+		FailedMsg:     "The payment did not go through. Please try again with another card.",
+		Refunded:      "Payment refunded",
+		RefundedMsg:   "The amount for this order has been returned to your card.",
+	},
+	payriff.LanguageRU: {
+		Redirecting:   "Перенаправляем на страницу оплаты",
+		RedirectHint:  "Если ничего не происходит, нажмите кнопку ниже.",
+		ContinueLabel: "Перейти к оплате",
+		OrderLabel:    "Заказ",
+		AmountLabel:   "Сумма",
+		BackLabel:     "Вернуться в магазин",
+		Approved:      "Оплата прошла успешно",
+		ApprovedMsg:   "Спасибо! Ваш платёж получен.",
+		Pending:       "Платёж обрабатывается",
+		PendingMsg:    "Платёж ещё не завершён. Проверьте ещё раз через несколько минут.",
+		Failed:        "Оплата не прошла",
+		FailedMsg:     "Платёж не был выполнен. Попробуйте ещё раз с другой картой.",
+		Refunded:      "Платёж возвращён",
+		RefundedMsg:   "Сумма по этому заказу возвращена на вашу карту.",
+	},
+}
+
+func textsFor(lang payriff.Language) texts {
+	if t, ok := translations[lang]; ok {
+		return t
+	}
+	return translations[payriff.LanguageAZ]
+}
+
+// outcome returns the heading and message for a payment status
+func (t texts) outcome(status payriff.Status) (string, string) {
+	switch status {
+	case payriff.StatusApproved, payriff.StatusPreAuthApproved:
+		return t.Approved, t.ApprovedMsg
+	case payriff.StatusRefunded, payriff.StatusPartialRefund, payriff.StatusReverse:
+		return t.Refunded, t.RefundedMsg
+	case payriff.StatusCreated:
+		return t.Pending, t.PendingMsg
+	default:
+		return t.Failed, t.FailedMsg
+	}
+}
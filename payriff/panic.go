@@ -0,0 +1,57 @@
+package payriff
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned when a user-supplied hook, verifier, notifier or
+// handler panics. The panic is recovered so it cannot crash the payment path
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("payriff: recovered panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value when it is an error
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// safeCall runs fn, converting a panic into a *PanicError
+func safeCall(fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+// runHook runs a user hook, reporting a panic through Hooks.OnError
+func (s *SDK) runHook(fn func()) {
+	err := safeCall(func() error {
+		fn()
+		return nil
+	})
+	if err != nil {
+		s.reportError(err)
+	}
+}
+
+// reportError passes err to Hooks.OnError, ignoring panics in the reporter
+func (s *SDK) reportError(err error) {
+	if s.hooks.OnError == nil {
+		return
+	}
+	_ = safeCall(func() error {
+		s.hooks.OnError(err)
+		return nil
+	})
+}
@@ -2,29 +2,168 @@ package payriff
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
+	"time"
 )
 
 // Config holds the configuration for the Payriff SDK
 type Config struct {
-	BaseURL            string
-	SecretKey          string
+	BaseURL string
+	// APIVersion is the version path segment, defaults to the version at the
+	// end of BaseURL
+	APIVersion string
+	// Environment marks responses as test mode and, for production, sets
+	// the default BaseURL. It defaults to PAYRIFF_ENVIRONMENT and then
+	// EnvironmentProduction. The sandbox requires BaseURL, and rejects the
+	// production host
+	Environment Environment
+	SecretKey   string
+	// PublicKey is used instead of SecretKey for read-only calls such as
+	// GetOrderInfo. It is never read from the environment, so public-key
+	// reads are opt-in
+	PublicKey string
+	// ReadOnly configures a service that only polls order status, so it
+	// validates with PublicKey alone. Other configs need SecretKey, Keys or
+	// Auth
+	ReadOnly bool
+	// Keys lists secret keys for zero-downtime rotation. The most recently
+	// activated key is used and its ID sent in the X-Payriff-Key-Id header.
+	// Keys takes precedence over SecretKey
+	Keys               []APIKey
 	DefaultCallbackURL string
 	DefaultLanguage    Language
 	DefaultCurrency    Currency
+	// DefaultTheme is applied to orders that set no Theme
+	DefaultTheme *PageTheme
+	// CardSaveAmount is the amount SaveCard pre-authorizes when the request
+	// sets none, defaults to one minor unit
+	CardSaveAmount Amount
+	// ErrorOnFailure returns responses with a non-success result code as
+	// *APIError instead of a response the caller must check
+	ErrorOnFailure bool
+	// AmountPolicy enforces amount limits before charges and refunds
+	AmountPolicy *AmountPolicy
+	// Capabilities validates operation combinations before they are sent,
+	// defaults to DefaultCapabilities
+	Capabilities CapabilityMatrix
+	// Features enables or disables merchant features, overriding the
+	// capability matrix
+	Features FeatureSet
+	// Retry enables automatic retries of transient read failures
+	Retry *RetryPolicy
+	// Hooks receive events about SDK behavior such as retries
+	Hooks Hooks
+	// DegradedMode serves cached order info and fails writes fast with
+	// ErrGatewayUnavailable while the gateway is unreachable
+	DegradedMode bool
+	// DegradedThreshold is the number of consecutive transport failures
+	// that flag the gateway unreachable, defaults to 3
+	DegradedThreshold int
+	// DegradedRecovery is how long the gateway stays flagged unreachable
+	// without a successful probe, defaults to 30 seconds
+	DegradedRecovery time.Duration
+	// Auth replaces the static key with another authentication scheme,
+	// such as TokenAuth
+	Auth Authenticator
+	// Signing enables HMAC signing of API requests
+	Signing *RequestSigning
+	// Profiles holds named environment settings. Profile (or the
+	// PAYRIFF_PROFILE environment variable) selects one, and its values fill
+	// in fields not set directly in Config
+	Profiles map[string]Profile
+	Profile  string
+	// Shadow mirrors a share of read requests to another base URL or API
+	// version for comparison
+	Shadow *ShadowConfig
+	// CanonicalJSON serializes request bodies with sorted keys so identical
+	// requests are byte-for-byte identical
+	CanonicalJSON bool
+	// Audit records every API call with its body hash
+	Audit AuditSink
+	// IdempotencyKeys generates idempotency keys for mutating calls made
+	// without one, such as UUIDKeys
+	IdempotencyKeys IdempotencyKeyGenerator
+	// IdempotencyStore rejects reuse of an idempotency key with a different
+	// request body
+	IdempotencyStore IdempotencyStore
+	// DetectDrift compares decoded responses with their raw payloads and
+	// reports unknown or missing fields through Hooks.OnDrift
+	DetectDrift bool
+	// Envelope overrides the response envelope profile, which otherwise
+	// comes from EnvelopeProfiles for the API version
+	Envelope *EnvelopeProfile
+	// Logger logs every API call with its result code and latency. Bodies
+	// are logged at debug level with secrets and card data redacted
+	Logger *slog.Logger
+	// HTTPClient sends API requests, so timeouts, proxies and TLS settings
+	// can be configured. Defaults to a client with Timeout
+	HTTPClient *http.Client
+	// Timeout bounds each attempt of an API call. It defaults to
+	// DefaultTimeout for the client the SDK creates, and replaces the
+	// timeout of HTTPClient when set. A negative value disables it; bound
+	// whole calls with WithTimeout or WithDeadline
+	Timeout time.Duration
+	// TransportTimeouts bound dialing, the TLS handshake and the wait for
+	// response headers on the transport the SDK creates
+	TransportTimeouts TransportTimeouts
+	// Transport replaces the RoundTripper of HTTPClient, e.g. for
+	// instrumentation. The client given in HTTPClient is not modified
+	Transport http.RoundTripper
+	// Metrics observes every attempt of an API call
+	Metrics MetricsCollector
+	// URLShortener shortens the payment URLs of orders and invoices,
+	// defaults to NoopShortener
+	URLShortener URLShortener
+	// RefundLedger tracks refunded amounts per order, defaults to a
+	// MemoryRefundLedger
+	RefundLedger RefundLedger
 }
 
 // SDK represents the Payriff payment gateway client
 type SDK struct {
 	baseURL            string
+	apiVersion         string
+	environment        Environment
 	secretKey          string
+	publicKey          string
+	readOnly           bool
+	keys               []APIKey
 	defaultCallbackURL string
 	defaultLanguage    Language
 	defaultCurrency    Currency
+	defaultTheme       *PageTheme
+	cardSaveAmount     Amount
+	capabilities       CapabilityMatrix
+	featureOverrides   FeatureSet
+	amountPolicy       *AmountPolicy
+	errorOnFailure     bool
+	idempotencyKeys    IdempotencyKeyGenerator
+	idempotencyStore   IdempotencyStore
+	degradedMode       bool
+	signing            *RequestSigning
+	auth               Authenticator
+	retry              *RetryPolicy
+	hooks              Hooks
 	client             *http.Client
+	health             *health
+	configErr          error
+	shadow             *shadowRoute
+	deprecations       *deprecations
+	canonicalJSON      bool
+	auditSink          AuditSink
+	drift              *driftCounts
+	envelopeProfile    *EnvelopeProfile
+	logger             *slog.Logger
+	metrics            MetricsCollector
+	shortener          URLShortener
+	refundLedger       RefundLedger
 }
 
 // Language represents supported language codes
@@ -86,9 +225,9 @@ const (
 
 // OrderPayload represents the response payload for order creation
 type OrderPayload struct {
-	OrderID       string `json:"orderId"`
-	PaymentURL    string `json:"paymentUrl"`
-	TransactionID int64  `json:"transactionId"`
+	OrderID       OrderID `json:"orderId"`
+	PaymentURL    string  `json:"paymentUrl"`
+	TransactionID int64   `json:"transactionId"`
 }
 
 // CardDetails represents saved card information
@@ -110,18 +249,21 @@ type Transaction struct {
 	Pan              string      `json:"pan"`
 	PaymentWay       string      `json:"paymentWay"`
 	CardDetails      CardDetails `json:"cardDetails"`
-	CardUUID         *string     `json:"cardUuid,omitempty"`
+	CardUUID         *CardUUID   `json:"cardUuid,omitempty"`
 	MerchantCategory string      `json:"merchantCategory"`
 	Installment      struct {
 		Type   *string `json:"type"`
 		Period *string `json:"period"`
 	} `json:"installment"`
 	DeliveryAddress *string `json:"deliveryAddress"`
+	// ResponseCode is the issuer response code, set on declined transactions
+	ResponseCode    string `json:"responseCode,omitempty"`
+	ResponseMessage string `json:"responseMessage,omitempty"`
 }
 
 // OrderInfo represents detailed order information
 type OrderInfo struct {
-	OrderID        string        `json:"orderId"`
+	OrderID        OrderID       `json:"orderId"`
 	InvoiceUUID    *string       `json:"invoiceUuid"`
 	Amount         float64       `json:"amount"`
 	CurrencyType   Currency      `json:"currencyType"`
@@ -133,39 +275,87 @@ type OrderInfo struct {
 	CreatedDate    string        `json:"createdDate"`
 	Description    string        `json:"description"`
 	Transactions   []Transaction `json:"transactions,omitempty"`
+	// Settlement fields are set when the gateway settles in a different
+	// currency than the order
+	SettlementAmount   *float64  `json:"settlementAmount,omitempty"`
+	SettlementCurrency *Currency `json:"settlementCurrency,omitempty"`
+	ConversionRate     *float64  `json:"conversionRate,omitempty"`
+	TerminalID         string    `json:"terminalId,omitempty"`
+	// Customer is set when the order was created with one
+	Customer *Customer `json:"customer,omitempty"`
+}
+
+// Settlement returns the settled amount and currency, falling back to the
+// order amount and currency when the order settled without conversion
+func (o OrderInfo) Settlement() (float64, Currency) {
+	if o.SettlementAmount != nil && o.SettlementCurrency != nil {
+		return *o.SettlementAmount, *o.SettlementCurrency
+	}
+	return o.Amount, o.CurrencyType
 }
 
 // CreateOrderRequest represents parameters for creating a new order
 type CreateOrderRequest struct {
-	Amount      float64   `json:"amount"`
+	Amount      Amount    `json:"amount"`
 	Description string    `json:"description"`
 	CardSave    bool      `json:"cardSave"`
 	Operation   Operation `json:"operation,omitempty"`
 	Language    Language  `json:"language,omitempty"`
 	Currency    Currency  `json:"currency,omitempty"`
 	CallbackURL string    `json:"callbackUrl,omitempty"`
+	// Theme customizes the hosted payment page, defaults to Config.DefaultTheme
+	Theme *PageTheme `json:"theme,omitempty"`
+	// TerminalID attributes the order to a store, branch or till
+	TerminalID string `json:"terminalId,omitempty"`
+	// Installment lets the shopper pay in installments
+	Installment *Installment `json:"installment,omitempty"`
+	// Customer is printed on the receipt
+	Customer *Customer `json:"customer,omitempty"`
+}
+
+// PageTheme customizes the hosted payment page to match a storefront. The
+// page language is chosen by the order's Language
+type PageTheme struct {
+	// Color is the accent color in #RRGGBB form
+	Color       string `json:"themeColor,omitempty"`
+	LogoURL     string `json:"logoUrl,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
 }
 
 // RefundRequest represents parameters for refund operation
 type RefundRequest struct {
-	Amount  float64 `json:"amount"`
-	OrderID string  `json:"orderId"`
+	Amount  Amount  `json:"amount"`
+	OrderID OrderID `json:"orderId"`
+	// Destination refunds to another card instead of the original one. The
+	// response payload is then a TopupPayload
+	Destination *RefundDestination `json:"-"`
 }
 
 // CompleteRequest represents parameters for complete operation
 type CompleteRequest struct {
-	Amount  float64 `json:"amount"`
-	OrderID string  `json:"orderId"`
+	Amount  Amount  `json:"amount"`
+	OrderID OrderID `json:"orderId"`
+}
+
+// CompletePayload is the result of completing a pre-authorized payment
+type CompletePayload struct {
+	OrderID       OrderID       `json:"orderId"`
+	Amount        float64       `json:"amount"`
+	CurrencyType  Currency      `json:"currencyType"`
+	PaymentStatus Status        `json:"paymentStatus"`
+	Transactions  []Transaction `json:"transactions,omitempty"`
 }
 
 // AutoPayRequest represents parameters for automatic payment
 type AutoPayRequest struct {
-	CardUUID    string    `json:"cardUuid"`
-	Amount      float64   `json:"amount"`
+	CardUUID    CardUUID  `json:"cardUuid"`
+	Amount      Amount    `json:"amount"`
 	Description string    `json:"description"`
 	Operation   Operation `json:"operation,omitempty"`
 	Currency    Currency  `json:"currency,omitempty"`
 	CallbackURL string    `json:"callbackUrl,omitempty"`
+	// Installment charges the saved card in installments
+	Installment *Installment `json:"installment,omitempty"`
 }
 
 // Response represents the base API response structure
@@ -186,13 +376,70 @@ type ApiResponse[T any] struct {
 	InternalMessage *string    `json:"internalMessage"`
 	ResponseID      string     `json:"responseId"`
 	Payload         T          `json:"payload"`
+	// Stale is set when the payload was served from cache in degraded mode
+	Stale bool `json:"-"`
+	// TestMode is set when the SDK is configured for the sandbox
+	TestMode bool `json:"-"`
+}
+
+// IsSuccessful reports whether the response carries a success code
+func (r *Response) IsSuccessful() bool {
+	return r.Code.IsSuccessful()
+}
+
+// IsSuccessful reports whether the response carries a success code
+func (r *ApiResponse[T]) IsSuccessful() bool {
+	return r.Code.IsSuccessful()
+}
+
+// Err returns nil for a successful response, and an *APIError carrying the
+// result code otherwise
+func (r *ApiResponse[T]) Err() error {
+	if r.IsSuccessful() {
+		return nil
+	}
+	return newAPIError(&Response{
+		Code:            r.Code,
+		Message:         r.Message,
+		Route:           r.Route,
+		InternalMessage: r.InternalMessage,
+		ResponseID:      r.ResponseID,
+	})
 }
 
 // NewSDK creates a new instance of the Payriff SDK
 func NewSDK(config Config) *SDK {
+	// Apply the selected profile
+	if config.Profile == "" {
+		config.Profile = os.Getenv("PAYRIFF_PROFILE")
+	}
+	var configErr error
+	if config.Profile != "" {
+		configErr = applyProfile(&config, config.Profile)
+	}
+
+	// Select the environment
+	if config.Environment == "" {
+		config.Environment = Environment(os.Getenv("PAYRIFF_ENVIRONMENT"))
+	}
+	if config.Environment == "" {
+		config.Environment = EnvironmentProduction
+	}
+	environment, err := parseEnvironment(string(config.Environment))
+	if err == nil {
+		err = checkEnvironment(environment, config.BaseURL)
+	}
+	configErr = errors.Join(configErr, err)
+
 	// Set default base URL
 	if config.BaseURL == "" {
-		config.BaseURL = "https://api.payriff.com/api/v3"
+		config.BaseURL = environment.BaseURL()
+	}
+
+	// Split the version segment off the base URL
+	baseURL, version := splitBaseURL(config.BaseURL)
+	if config.APIVersion == "" {
+		config.APIVersion = version
 	}
 
 	// Set default secret key from environment
@@ -205,6 +452,11 @@ func NewSDK(config Config) *SDK {
 		config.DefaultCallbackURL = os.Getenv("PAYRIFF_CALLBACK_URL")
 	}
 
+	// Set default card verification amount
+	if !config.CardSaveAmount.IsPositive() {
+		config.CardSaveAmount = MinorUnits(1)
+	}
+
 	// Set default language
 	if config.DefaultLanguage == "" {
 		config.DefaultLanguage = LanguageAZ
@@ -215,50 +467,237 @@ func NewSDK(config Config) *SDK {
 		config.DefaultCurrency = CurrencyAZN
 	}
 
-	return &SDK{
-		baseURL:            config.BaseURL,
+	// Set default capability matrix
+	if config.Capabilities == nil {
+		config.Capabilities = DefaultCapabilities
+	}
+
+	s := &SDK{
+		baseURL:            baseURL,
+		apiVersion:         config.APIVersion,
+		environment:        environment,
 		secretKey:          config.SecretKey,
+		publicKey:          config.PublicKey,
+		readOnly:           config.ReadOnly,
+		keys:               append([]APIKey(nil), config.Keys...),
 		defaultCallbackURL: config.DefaultCallbackURL,
 		defaultLanguage:    config.DefaultLanguage,
 		defaultCurrency:    config.DefaultCurrency,
-		client:             &http.Client{},
+		defaultTheme:       config.DefaultTheme,
+		cardSaveAmount:     config.CardSaveAmount,
+		capabilities:       config.Capabilities,
+		featureOverrides:   config.Features,
+		amountPolicy:       config.AmountPolicy,
+		errorOnFailure:     config.ErrorOnFailure,
+		idempotencyKeys:    config.IdempotencyKeys,
+		idempotencyStore:   config.IdempotencyStore,
+		degradedMode:       config.DegradedMode,
+		signing:            config.Signing,
+		auth:               config.Auth,
+		retry:              config.Retry,
+		hooks:              config.Hooks,
+		client:             newHTTPClient(config.HTTPClient, config.Transport, config.Timeout, config.TransportTimeouts),
+		health:             newHealth(config.DegradedThreshold, config.DegradedRecovery),
+		configErr:          configErr,
+		deprecations:       &deprecations{},
+		canonicalJSON:      config.CanonicalJSON,
+		auditSink:          config.Audit,
+		envelopeProfile:    config.Envelope,
+		logger:             config.Logger,
+		metrics:            config.Metrics,
+		shortener:          config.URLShortener,
+		refundLedger:       config.RefundLedger,
+	}
+	if config.DetectDrift {
+		s.drift = &driftCounts{}
+	}
+	if s.refundLedger == nil {
+		s.refundLedger = &MemoryRefundLedger{}
+	}
+	if config.Shadow != nil {
+		s.shadow = newShadowRoute(s, config.Shadow)
 	}
+	return s
+}
+
+// newHTTPClient returns client, or a new client with the transport
+// timeouts, using transport and timeout when set. client is not modified
+func newHTTPClient(client *http.Client, transport http.RoundTripper, timeout time.Duration, timeouts TransportTimeouts) *http.Client {
+	if client == nil {
+		if transport == nil {
+			transport = timeouts.transport()
+		}
+		if timeout == 0 {
+			timeout = DefaultTimeout
+		}
+		return &http.Client{Transport: transport, Timeout: max(timeout, 0)}
+	}
+	if transport == nil && timeout == 0 {
+		return client
+	}
+	c := *client
+	if transport != nil {
+		c.Transport = transport
+	}
+	if timeout != 0 {
+		c.Timeout = max(timeout, 0)
+	}
+	return &c
 }
 
 // makeRequest handles HTTP requests to the Payriff API
-func (s *SDK) makeRequest(endpoint string, method string, body interface{}) (*Response, error) {
-	var buf bytes.Buffer
-	if body != nil {
-		if err := json.NewEncoder(&buf).Encode(body); err != nil {
-			return nil, fmt.Errorf("failed to encode request body: %w", err)
+func (s *SDK) makeRequest(ctx context.Context, endpoint string, method string, scope KeyScope, body interface{}) (*Response, error) {
+	if s.configErr != nil {
+		return nil, s.configErr
+	}
+	if info, ok := LookupEndpoint(method, endpoint); ok && info.Feature != "" {
+		if err := s.requireFeatures(info.Name, info.Feature); err != nil {
+			return nil, err
 		}
 	}
 
-	req, err := http.NewRequest(method, s.baseURL+endpoint, &buf)
+	key, err := s.keyFor(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := s.encodeBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if method != http.MethodGet {
+		if ctx, err = s.idempotent(ctx, method, endpoint, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := s.withRetries(ctx, method, endpoint, func() (*Response, error) {
+		return s.doRequest(ctx, endpoint, method, key, payload)
+	})
+	s.audit(ctx, method, endpoint, payload, resp, err)
+	if err == nil {
+		s.mirror(ctx, endpoint, method, key, resp)
+	}
+	return resp, err
+}
+
+// doRequest performs a single attempt of an API request
+func (s *SDK) doRequest(ctx context.Context, endpoint string, method string, key APIKey, payload []byte) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.apiURL(endpoint), bytes.NewReader(payload))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", s.secretKey)
+	for k, v := range requestHeader(ctx) {
+		req.Header[k] = v
+	}
 	req.Header.Set("Content-Type", "application/json")
+	if key := idempotencyKey(ctx); key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	if s.auth != nil {
+		if err := s.auth.Authenticate(ctx, req); err != nil {
+			return nil, fmt.Errorf("failed to authenticate request: %w", err)
+		}
+	} else {
+		req.Header.Set("Authorization", key.Secret)
+		if key.ID != "" {
+			req.Header.Set(HeaderKeyID, key.ID)
+		}
+	}
+	if s.signing != nil {
+		if err := s.signRequest(req, key, payload); err != nil {
+			return nil, err
+		}
+	}
 
+	start := time.Now()
+	result, status, data, err := s.roundTrip(req)
+	err = maintenanceFromHTTP(err)
+	latency := time.Since(start)
+	s.logCall(ctx, method, endpoint, payload, status, data, result, latency, err)
+	s.observeCall(ctx, method, endpoint, status, result, latency, err)
+	return result, err
+}
+
+// roundTrip sends req and decodes the envelope, also returning the HTTP
+// status and raw body for logging
+func (s *SDK) roundTrip(req *http.Request) (*Response, int, []byte, error) {
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		s.markTransportFailure(req.Context())
+		return nil, 0, nil, fmt.Errorf("failed to make request: %w", err)
 	}
 	defer resp.Body.Close()
+	s.markTransportSuccess()
 
-	var result Response
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return s.errorResponse(resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	result, err := decodeEnvelope(data, s.envelope())
+	if err != nil {
+		return nil, resp.StatusCode, data, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return &result, nil
+	return result, resp.StatusCode, data, nil
+}
+
+// errorResponse handles a non-2xx response. A body carrying an envelope
+// with a result code is decoded as usual, so gateway errors keep their
+// code; any other body, and transient statuses, become an *HTTPError
+func (s *SDK) errorResponse(resp *http.Response) (*Response, int, []byte, error) {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	httpErr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header.Clone(),
+		Body:       data,
+	}
+	if !httpErr.Temporary() {
+		if result, err := decodeEnvelope(data, s.envelope()); err == nil && result.Code != "" {
+			return result, resp.StatusCode, data, nil
+		}
+	}
+	if len(data) > maxErrorBody {
+		httpErr.Body = data[:maxErrorBody:maxErrorBody]
+		httpErr.Truncated = true
+	}
+	return nil, resp.StatusCode, httpErr.Body, httpErr
 }
 
 // CreateOrder creates a new payment order
+//
+// Deprecated: Use CreateOrderContext
 func (s *SDK) CreateOrder(req CreateOrderRequest) (*ApiResponse[OrderPayload], error) {
-	// Apply defaults if values are not provided
+	s.deprecated("SDK.CreateOrder", "SDK.CreateOrderContext")
+	return s.CreateOrderContext(context.Background(), req)
+}
+
+// CreateOrderContext creates a new payment order
+func (s *SDK) CreateOrderContext(ctx context.Context, req CreateOrderRequest, opts ...RequestOption) (*ApiResponse[OrderPayload], error) {
+	ctx, o, cancel := withOptions(ctx, opts)
+	defer cancel()
+
+	// Apply overrides, then defaults if values are not provided
+	if o.Language != "" {
+		req.Language = o.Language
+	}
+	if o.Currency != "" {
+		req.Currency = o.Currency
+	}
+	if o.CallbackURL != "" {
+		req.CallbackURL = o.CallbackURL
+	}
 	if req.Language == "" {
 		req.Language = s.defaultLanguage
 	}
@@ -271,80 +710,216 @@ func (s *SDK) CreateOrder(req CreateOrderRequest) (*ApiResponse[OrderPayload], e
 	if req.Operation == "" {
 		req.Operation = OperationPurchase
 	}
+	if req.Theme == nil {
+		req.Theme = s.defaultTheme
+	}
 
-	resp, err := s.makeRequest("/orders", http.MethodPost, req)
-	if err != nil {
+	if req.Installment != nil {
+		installment, err := req.Installment.normalize()
+		if err != nil {
+			return nil, err
+		}
+		req.Installment = installment
+	}
+	if err := s.requireFeatures("CreateOrderContext", operationFeatures(req.Operation, req.CardSave, req.Installment != nil)...); err != nil {
+		return nil, err
+	}
+	if err := s.capabilities.Check(OperationParams{
+		Operation:   req.Operation,
+		Currency:    req.Currency,
+		CardSave:    req.CardSave,
+		Installment: req.Installment != nil,
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.checkWritable(); err != nil {
 		return nil, err
 	}
 
-	var result ApiResponse[OrderPayload]
-	if err := json.Unmarshal(resp.Payload, &result.Payload); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal order payload: %w", err)
+	operator, release, err := s.amountPolicy.charge(ctx, req.Currency, req.Amount)
+	if err != nil {
+		return nil, err
 	}
+	ctx = withOverriddenBy(ctx, operator)
 
-	// Copy response metadata
-	result.Code = resp.Code
-	result.Message = resp.Message
-	result.Route = resp.Route
-	result.InternalMessage = resp.InternalMessage
-	result.ResponseID = resp.ResponseID
+	resp, err := s.makeRequest(ctx, "/orders", http.MethodPost, ScopeSecret, req)
+	if err != nil {
+		release()
+		return nil, err
+	}
 
-	return &result, nil
+	result, err := decodeResponse[OrderPayload](s, "POST /orders", resp)
+	if err != nil || !result.IsSuccessful() {
+		release()
+	} else {
+		bindOrder(ctx, result.Payload.OrderID)
+		result.Payload.PaymentURL = s.shortenURL(ctx, result.Payload.PaymentURL)
+	}
+	return result, err
 }
 
 // GetOrderInfo retrieves information about an existing order
-func (s *SDK) GetOrderInfo(orderID string) (*ApiResponse[OrderInfo], error) {
-	resp, err := s.makeRequest(fmt.Sprintf("/orders/%s", orderID), http.MethodGet, nil)
+//
+// Deprecated: Use GetOrderInfoContext
+func (s *SDK) GetOrderInfo(orderID OrderID) (*ApiResponse[OrderInfo], error) {
+	s.deprecated("SDK.GetOrderInfo", "SDK.GetOrderInfoContext")
+	return s.GetOrderInfoContext(context.Background(), orderID)
+}
+
+// GetOrderInfoContext retrieves information about an existing order
+func (s *SDK) GetOrderInfoContext(ctx context.Context, orderID OrderID, opts ...RequestOption) (*ApiResponse[OrderInfo], error) {
+	ctx, _, cancel := withOptions(ctx, opts)
+	defer cancel()
+
+	// Serve cached data while the gateway is down
+	if stale, ok := s.staleOrder(orderID); ok {
+		return stale, nil
+	}
+
+	resp, err := s.makeRequest(ctx, fmt.Sprintf("/orders/%s", orderID), http.MethodGet, ScopePublic, nil)
 	if err != nil {
+		if stale, ok := s.staleOrder(orderID); ok {
+			return stale, nil
+		}
 		return nil, err
 	}
 
-	var result ApiResponse[OrderInfo]
-	if err := json.Unmarshal(resp.Payload, &result.Payload); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal order info: %w", err)
+	result, err := decodeResponse[OrderInfo](s, "GET /orders/{orderId}", resp)
+	if err != nil {
+		return nil, err
 	}
 
-	// Copy response metadata
-	result.Code = resp.Code
-	result.Message = resp.Message
-	result.Route = resp.Route
-	result.InternalMessage = resp.InternalMessage
-	result.ResponseID = resp.ResponseID
+	if result.IsSuccessful() {
+		s.cacheOrder(result.Payload)
+	}
 
-	return &result, nil
+	return result, nil
 }
 
 // Refund initiates a refund for an order
+//
+// Deprecated: Use RefundContext
 func (s *SDK) Refund(req RefundRequest) (*ApiResponse[json.RawMessage], error) {
-	resp, err := s.makeRequest("/refund", http.MethodPost, req)
-	if err != nil {
+	s.deprecated("SDK.Refund", "SDK.RefundContext")
+	return s.RefundContext(context.Background(), req)
+}
+
+// RefundContext initiates a refund for an order. With refund limits in the
+// AmountPolicy, the order is looked up to check the refund in its currency
+func (s *SDK) RefundContext(ctx context.Context, req RefundRequest, opts ...RequestOption) (*ApiResponse[json.RawMessage], error) {
+	ctx, _, cancel := withOptions(ctx, opts)
+	defer cancel()
+
+	if err := s.checkWritable(); err != nil {
 		return nil, err
 	}
+	if s.amountPolicy.limitsRefunds() && ctx.Value(approvedRefundCtx{}) == nil {
+		info, err := s.GetOrderInfoContext(ctx, req.OrderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up order currency: %w", err)
+		}
+		if !info.IsSuccessful() {
+			return nil, info.Err()
+		}
+		operator, err := s.amountPolicy.checkRefund(ctx, info.Payload.CurrencyType, req.OrderID, req.Amount)
+		if err != nil {
+			return nil, err
+		}
+		ctx = withOverriddenBy(ctx, operator)
+	}
 
-	var result ApiResponse[json.RawMessage]
-	result.Payload = resp.Payload
-	result.Code = resp.Code
-	result.Message = resp.Message
-	result.Route = resp.Route
-	result.InternalMessage = resp.InternalMessage
-	result.ResponseID = resp.ResponseID
+	if req.Destination != nil {
+		return s.refundToCard(ctx, req)
+	}
+
+	resp, err := s.makeRequest(ctx, "/refund", http.MethodPost, ScopeSecret, req)
+	if err != nil {
+		return nil, err
+	}
 
-	return &result, nil
+	result, err := decodeResponse[json.RawMessage](s, "POST /refund", resp)
+	if err == nil && result.IsSuccessful() {
+		// Count gateway refunds so later refunds to another card see them
+		if err := s.refundLedger.Reserve(ctx, req.OrderID, req.Amount, Amount{}); err != nil {
+			s.reportError(fmt.Errorf("failed to record refund of %s for order %s: %w", req.Amount, req.OrderID, err))
+		}
+	}
+	return result, err
 }
 
 // Complete completes a pre-authorized payment
-func (s *SDK) Complete(req CompleteRequest) error {
-	_, err := s.makeRequest("/complete", http.MethodPost, req)
+//
+// Deprecated: Use CompleteContext
+func (s *SDK) Complete(req CompleteRequest) (*ApiResponse[CompletePayload], error) {
+	s.deprecated("SDK.Complete", "SDK.CompleteContext")
+	return s.CompleteContext(context.Background(), req)
+}
+
+// CompleteContext completes a pre-authorized payment
+func (s *SDK) CompleteContext(ctx context.Context, req CompleteRequest, opts ...RequestOption) (*ApiResponse[CompletePayload], error) {
+	ctx, _, cancel := withOptions(ctx, opts)
+	defer cancel()
+
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.makeRequest(ctx, "/complete", http.MethodPost, ScopeSecret, req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return decodeResponse[CompletePayload](s, "POST /complete", resp)
 }
 
 // AutoPay processes an automatic payment using saved card details
+//
+// Deprecated: Use ChargeSavedCard
 func (s *SDK) AutoPay(req AutoPayRequest) (*ApiResponse[OrderInfo], error) {
-	// Apply defaults if values are not provided
+	s.deprecated("SDK.AutoPay", "SDK.ChargeSavedCard")
+	return s.autoPayOrder(context.Background(), req, nil)
+}
+
+// AutoPayContext processes an automatic payment using saved card details
+// and decodes the payload as an OrderInfo
+//
+// Deprecated: Use ChargeSavedCard, whose AutoPayResult carries the decline
+// details of the charge
+func (s *SDK) AutoPayContext(ctx context.Context, req AutoPayRequest, opts ...RequestOption) (*ApiResponse[OrderInfo], error) {
+	s.deprecated("SDK.AutoPayContext", "SDK.ChargeSavedCard")
+	return s.autoPayOrder(ctx, req, opts)
+}
+
+func (s *SDK) autoPayOrder(ctx context.Context, req AutoPayRequest, opts []RequestOption) (*ApiResponse[OrderInfo], error) {
+	resp, err := s.autoPay(ctx, req, opts)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponse[OrderInfo](s, "POST /autoPay", resp)
+}
+
+// ChargeSavedCard processes an automatic payment using saved card details
+func (s *SDK) ChargeSavedCard(ctx context.Context, req AutoPayRequest, opts ...RequestOption) (*ApiResponse[AutoPayResult], error) {
+	resp, err := s.autoPay(ctx, req, opts)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponse[AutoPayResult](s, "POST /autoPay", resp)
+}
+
+// autoPay sends an AutoPay charge for ChargeSavedCard and the deprecated
+// AutoPay methods
+func (s *SDK) autoPay(ctx context.Context, req AutoPayRequest, opts []RequestOption) (*Response, error) {
+	ctx, o, cancel := withOptions(ctx, opts)
+	defer cancel()
+
+	// Apply overrides, then defaults if values are not provided
+	if o.Currency != "" {
+		req.Currency = o.Currency
+	}
+	if o.CallbackURL != "" {
+		req.CallbackURL = o.CallbackURL
+	}
 	if req.Currency == "" {
 		req.Currency = s.defaultCurrency
 	}
@@ -355,27 +930,51 @@ func (s *SDK) AutoPay(req AutoPayRequest) (*ApiResponse[OrderInfo], error) {
 		req.Operation = OperationPurchase
 	}
 
-	resp, err := s.makeRequest("/autoPay", http.MethodPost, req)
-	if err != nil {
+	if req.Installment != nil {
+		installment, err := req.Installment.normalize()
+		if err != nil {
+			return nil, err
+		}
+		req.Installment = installment
+	}
+	if err := s.requireFeatures("ChargeSavedCard", operationFeatures(req.Operation, false, req.Installment != nil)...); err != nil {
+		return nil, err
+	}
+	if err := s.capabilities.Check(OperationParams{
+		Operation:   req.Operation,
+		Currency:    req.Currency,
+		Installment: req.Installment != nil,
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.checkWritable(); err != nil {
 		return nil, err
 	}
 
-	var result ApiResponse[OrderInfo]
-	if err := json.Unmarshal(resp.Payload, &result.Payload); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal order info: %w", err)
+	operator, release, err := s.amountPolicy.charge(ctx, req.Currency, req.Amount)
+	if err != nil {
+		return nil, err
 	}
+	ctx = withOverriddenBy(ctx, operator)
 
-	// Copy response metadata
-	result.Code = resp.Code
-	result.Message = resp.Message
-	result.Route = resp.Route
-	result.InternalMessage = resp.InternalMessage
-	result.ResponseID = resp.ResponseID
+	resp, err := s.makeRequest(ctx, "/autoPay", http.MethodPost, ScopeSecret, req)
+	if err != nil {
+		release()
+		return nil, err
+	}
 
-	return &result, nil
+	// Only approved charges count towards the daily cap
+	var result AutoPayResult
+	if !resp.IsSuccessful() || json.Unmarshal(resp.Payload, &result) != nil || !result.Approved() {
+		release()
+	}
+	return resp, nil
 }
 
 // IsSuccessful checks if an operation was successful based on the response code
+//
+// Deprecated: Use ApiResponse.IsSuccessful or ResultCode.IsSuccessful
 func (s *SDK) IsSuccessful(code ResultCode) bool {
-	return code == ResultCodeSuccess || code == ResultCodeSuccessGateway
+	s.deprecated("SDK.IsSuccessful", "ApiResponse.IsSuccessful")
+	return code.IsSuccessful()
 }
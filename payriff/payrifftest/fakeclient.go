@@ -0,0 +1,277 @@
+package payrifftest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kerimovok/payriff-sdk-go/payriff"
+)
+
+// Call is one call recorded by a FakeClient
+type Call struct {
+	// Method is the Client method, e.g. CreateOrderContext
+	Method string
+	// Request is the request argument, or the order ID for GetOrderInfoContext
+	Request any
+	// Options are the resolved per-call options
+	Options payriff.RequestOptions
+}
+
+// FakeClient is an in-memory payriff.Client for unit tests. It records
+// calls and simulates the order lifecycle without HTTP: orders are created
+// unpaid, paid with Approve, and refunded, completed or reversed through
+// the client. Canned responses queued with Respond take precedence. The
+// zero value is ready to use
+type FakeClient struct {
+	// Clock timestamps orders, defaults to the current time
+	Clock *Clock
+
+	mu     sync.Mutex
+	calls  []Call
+	canned map[string][]canned
+	ledger *ledger
+}
+
+type canned struct {
+	resp any
+	err  error
+}
+
+var _ payriff.Client = (*FakeClient)(nil)
+
+// Respond queues a canned answer for the next call to method, which
+// skips the simulated lifecycle. resp must match the method's response type
+func Respond[T any](f *FakeClient, method string, resp *payriff.ApiResponse[T], err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.canned == nil {
+		f.canned = make(map[string][]canned)
+	}
+	f.canned[method] = append(f.canned[method], canned{resp: resp, err: err})
+}
+
+// Fail queues err as the answer to the next call to method
+func (f *FakeClient) Fail(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.canned == nil {
+		f.canned = make(map[string][]canned)
+	}
+	f.canned[method] = append(f.canned[method], canned{err: err})
+}
+
+// Calls returns the recorded calls, optionally only those to methods
+func (f *FakeClient) Calls(methods ...string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(methods) == 0 {
+		return append([]Call(nil), f.calls...)
+	}
+	var calls []Call
+	for _, c := range f.calls {
+		for _, m := range methods {
+			if c.Method == m {
+				calls = append(calls, c)
+				break
+			}
+		}
+	}
+	return calls
+}
+
+// Order returns the simulated state of an order
+func (f *FakeClient) Order(id payriff.OrderID) (payriff.OrderInfo, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	o, ok := f.book().get(id)
+	if !ok {
+		return payriff.OrderInfo{}, false
+	}
+	return o.info, true
+}
+
+// SetStatus changes an order's status as the shopper or bank would
+func (f *FakeClient) SetStatus(id payriff.OrderID, status payriff.Status) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	o, ok := f.book().get(id)
+	if !ok {
+		return fmt.Errorf("payrifftest: unknown order %s", id)
+	}
+	f.book().setStatus(o, status)
+	return nil
+}
+
+// Approve pays an order, moving it to APPROVED, or PREAUTH_APPROVED for
+// pre-authorizations
+func (f *FakeClient) Approve(id payriff.OrderID) error {
+	info, ok := f.Order(id)
+	if !ok {
+		return fmt.Errorf("payrifftest: unknown order %s", id)
+	}
+	return f.SetStatus(id, paidStatus(info.OperationType))
+}
+
+// CreateOrderContext implements payriff.Client
+func (f *FakeClient) CreateOrderContext(ctx context.Context, req payriff.CreateOrderRequest, opts ...payriff.RequestOption) (*payriff.ApiResponse[payriff.OrderPayload], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if resp, err, ok := answer[payriff.OrderPayload](f, "CreateOrderContext", req, opts); ok {
+		return resp, err
+	}
+	if o := payriff.NewRequestOptions(opts...); o.Currency != "" {
+		req.Currency = o.Currency
+	}
+	o := f.book().create(req)
+	return success(payriff.OrderPayload{
+		OrderID:    o.info.OrderID,
+		PaymentURL: "https://payrifftest.invalid/pay/" + string(o.info.OrderID),
+	}), nil
+}
+
+// GetOrderInfoContext implements payriff.Client
+func (f *FakeClient) GetOrderInfoContext(ctx context.Context, orderID payriff.OrderID, opts ...payriff.RequestOption) (*payriff.ApiResponse[payriff.OrderInfo], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if resp, err, ok := answer[payriff.OrderInfo](f, "GetOrderInfoContext", orderID, opts); ok {
+		return resp, err
+	}
+	o, ok := f.book().get(orderID)
+	if !ok {
+		return rejected[payriff.OrderInfo](payriff.ResultCodeInvalidParameters, "order not found"), nil
+	}
+	return success(o.info), nil
+}
+
+// RefundContext implements payriff.Client
+func (f *FakeClient) RefundContext(ctx context.Context, req payriff.RefundRequest, opts ...payriff.RequestOption) (*payriff.ApiResponse[json.RawMessage], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if resp, err, ok := answer[json.RawMessage](f, "RefundContext", req, opts); ok {
+		return resp, err
+	}
+	o, code, message := f.book().refund(req)
+	if o == nil {
+		return rejected[json.RawMessage](code, message), nil
+	}
+
+	payload, err := json.Marshal(o.info)
+	if err != nil {
+		return nil, err
+	}
+	return success(json.RawMessage(payload)), nil
+}
+
+// CompleteContext implements payriff.Client
+func (f *FakeClient) CompleteContext(ctx context.Context, req payriff.CompleteRequest, opts ...payriff.RequestOption) (*payriff.ApiResponse[payriff.CompletePayload], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if resp, err, ok := answer[payriff.CompletePayload](f, "CompleteContext", req, opts); ok {
+		return resp, err
+	}
+	o, code, message := f.book().transition(req.OrderID, payriff.StatusPreAuthApproved, payriff.StatusApproved)
+	if o == nil {
+		return rejected[payriff.CompletePayload](code, message), nil
+	}
+	return success(payriff.CompletePayload{
+		OrderID:       o.info.OrderID,
+		Amount:        o.info.Amount,
+		CurrencyType:  o.info.CurrencyType,
+		PaymentStatus: o.info.PaymentStatus,
+		Transactions:  o.info.Transactions,
+	}), nil
+}
+
+// ChargeSavedCard implements payriff.Client. Charges are approved at once
+func (f *FakeClient) ChargeSavedCard(ctx context.Context, req payriff.AutoPayRequest, opts ...payriff.RequestOption) (*payriff.ApiResponse[payriff.AutoPayResult], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if resp, err, ok := answer[payriff.AutoPayResult](f, "ChargeSavedCard", req, opts); ok {
+		return resp, err
+	}
+	if req.Operation == "" {
+		req.Operation = payriff.OperationPurchase
+	}
+	if o := payriff.NewRequestOptions(opts...); o.Currency != "" {
+		req.Currency = o.Currency
+	}
+	o := f.book().autoPay(req)
+	f.book().setStatus(o, paidStatus(req.Operation))
+	return success(autoPayResult(o)), nil
+}
+
+// Reverse implements payriff.Client
+func (f *FakeClient) Reverse(ctx context.Context, req payriff.ReverseRequest, opts ...payriff.RequestOption) (*payriff.ApiResponse[payriff.ReversePayload], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if resp, err, ok := answer[payriff.ReversePayload](f, "Reverse", req, opts); ok {
+		return resp, err
+	}
+	o, code, message := f.book().transition(req.OrderID, payriff.StatusPreAuthApproved, payriff.StatusReverse)
+	if o == nil {
+		return rejected[payriff.ReversePayload](code, message), nil
+	}
+	return success(payriff.ReversePayload{
+		OrderID:       o.info.OrderID,
+		Amount:        o.info.Amount,
+		PaymentStatus: o.info.PaymentStatus,
+	}), nil
+}
+
+// answer records a call and pops its canned answer, if any. Callers hold f.mu
+func answer[T any](f *FakeClient, method string, req any, opts []payriff.RequestOption) (*payriff.ApiResponse[T], error, bool) {
+	f.calls = append(f.calls, Call{Method: method, Request: req, Options: payriff.NewRequestOptions(opts...)})
+
+	queue := f.canned[method]
+	if len(queue) == 0 {
+		return nil, nil, false
+	}
+	f.canned[method] = queue[1:]
+
+	c := queue[0]
+	if c.resp == nil {
+		return nil, c.err, true
+	}
+	resp, ok := c.resp.(*payriff.ApiResponse[T])
+	if !ok {
+		panic(fmt.Sprintf("payrifftest: canned response for %s is %T, want %T", method, c.resp, resp))
+	}
+	return resp, c.err, true
+}
+
+// book returns the order ledger, creating it on first use. Callers hold f.mu
+func (f *FakeClient) book() *ledger {
+	if f.ledger == nil {
+		f.ledger = &ledger{prefix: "FAKE", now: f.now}
+	}
+	return f.ledger
+}
+
+func (f *FakeClient) now() time.Time {
+	if f.Clock != nil {
+		return f.Clock.Now()
+	}
+	return time.Now()
+}
+
+func success[T any](payload T) *payriff.ApiResponse[T] {
+	return &payriff.ApiResponse[T]{Code: payriff.ResultCodeSuccess, Message: "OK", Payload: payload}
+}
+
+func rejected[T any](code payriff.ResultCode, message string) *payriff.ApiResponse[T] {
+	return &payriff.ApiResponse[T]{Code: code, Message: message}
+}
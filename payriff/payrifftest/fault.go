@@ -0,0 +1,163 @@
+package payrifftest
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// FaultError is the transport error injected by FaultTransport. It is a
+// net.Error, so the SDK treats it like a real network failure
+type FaultError struct {
+	Op string
+}
+
+func (e *FaultError) Error() string {
+	return "payrifftest: injected fault: " + e.Op
+}
+
+// Timeout implements net.Error
+func (e *FaultError) Timeout() bool { return true }
+
+// Temporary implements net.Error
+func (e *FaultError) Temporary() bool { return true }
+
+// FaultTransport is an http.RoundTripper that injects gateway misbehavior
+// at configurable rates between 0 and 1. Set it as Config.Transport in
+// tests or staging to verify payment flows survive a flaky gateway
+type FaultTransport struct {
+	// Base performs real requests, defaults to http.DefaultTransport
+	Base http.RoundTripper
+	// ErrorRate fails requests with a *FaultError before they are sent
+	ErrorRate float64
+	// StatusRate answers with 503 Service Unavailable without sending
+	StatusRate float64
+	// MalformedRate sends the request but truncates the response body
+	MalformedRate float64
+	// LatencyRate delays requests by Latency
+	LatencyRate float64
+	Latency     time.Duration
+	// Match limits faults to matching requests, defaults to all requests
+	Match func(r *http.Request) bool
+	// Seed makes the injected faults reproducible when non-zero
+	Seed uint64
+
+	dice dice
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *FaultTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t.Match != nil && !t.Match(r) {
+		return base.RoundTrip(r)
+	}
+
+	if t.roll(t.LatencyRate) {
+		timer := time.NewTimer(t.Latency)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return nil, r.Context().Err()
+		case <-timer.C:
+		}
+	}
+	if t.roll(t.ErrorRate) {
+		return nil, &FaultError{Op: r.Method + " " + r.URL.Path}
+	}
+	if t.roll(t.StatusRate) {
+		rec := httptest.NewRecorder()
+		http.Error(rec, "injected fault", http.StatusServiceUnavailable)
+		resp := rec.Result()
+		resp.Request = r
+		return resp, nil
+	}
+
+	resp, err := base.RoundTrip(r)
+	if err != nil || !t.roll(t.MalformedRate) {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body[:len(body)/2]))
+	resp.ContentLength = -1
+	resp.Header.Del("Content-Length")
+	return resp, nil
+}
+
+func (t *FaultTransport) roll(rate float64) bool {
+	return t.dice.roll(t.Seed, rate)
+}
+
+// dice is a lazily seeded random source safe for concurrent use
+type dice struct {
+	once sync.Once
+	mu   sync.Mutex
+	rng  *rand.Rand
+}
+
+// roll reports whether an event with the given rate happens
+func (d *dice) roll(seed uint64, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	d.once.Do(func() {
+		if seed == 0 {
+			seed = rand.Uint64()
+		}
+		d.rng = rand.New(rand.NewPCG(seed, seed))
+	})
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.rng.Float64() < rate
+}
+
+// DuplicateCallbacks wraps a callback handler and delivers a share of
+// requests twice, as gateways do when an acknowledgement is lost. The
+// duplicate follows the original after Delay
+type DuplicateCallbacks struct {
+	Handler http.Handler
+	// Rate is the share of requests delivered twice, between 0 and 1
+	Rate float64
+	// Delay separates the duplicate from the original
+	Delay time.Duration
+	// Seed makes duplicates reproducible when non-zero
+	Seed uint64
+
+	dice dice
+}
+
+// ServeHTTP implements http.Handler. The duplicate's response is discarded
+func (d *DuplicateCallbacks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !d.dice.roll(d.Seed, d.Rate) {
+		d.Handler.ServeHTTP(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	clone := func() *http.Request {
+		c := r.Clone(r.Context())
+		c.Body = io.NopCloser(bytes.NewReader(body))
+		return c
+	}
+
+	d.Handler.ServeHTTP(w, clone())
+	if d.Delay > 0 {
+		time.Sleep(d.Delay)
+	}
+	d.Handler.ServeHTTP(httptest.NewRecorder(), clone())
+}
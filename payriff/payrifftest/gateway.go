@@ -0,0 +1,318 @@
+// Package payrifftest provides a fake Payriff gateway, a controllable clock
+// and a scenario DSL for end-to-end tests of payment flows.
+package payrifftest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kerimovok/payriff-sdk-go/payriff"
+)
+
+// SecretKey is the key set by Gateway.SDK. The gateway accepts any key
+const SecretKey = "payrifftest-secret"
+
+// Gateway is an in-process fake of the gateway API covering orders,
+// refunds, completion, reversal and automatic payments. Order state can be
+// driven from tests, and outcomes of calls scripted with Script
+type Gateway struct {
+	// Clock drives order expiry and timestamps
+	Clock *Clock
+	// OrderTTL is how long a created order can be paid, defaults to 30 minutes
+	OrderTTL time.Duration
+	// CallbackSecret signs callbacks with payriff.CallbackSignature when set
+	CallbackSecret string
+	// Timeout bounds how long OutcomeTimeout holds a request, defaults to
+	// a minute
+	Timeout time.Duration
+
+	server    *httptest.Server
+	closed    chan struct{}
+	closeOnce sync.Once
+	mu        sync.Mutex
+	ledger    *ledger
+	scripts   map[string][]Outcome
+}
+
+// NewGateway starts a fake gateway. Close it when the test ends
+func NewGateway(clock *Clock) *Gateway {
+	if clock == nil {
+		clock = NewClock(time.Now())
+	}
+	g := &Gateway{Clock: clock, closed: make(chan struct{})}
+	g.ledger = &ledger{prefix: "ORD", now: func() time.Time { return g.Clock.Now() }}
+	g.server = httptest.NewServer(http.HandlerFunc(g.serve))
+	return g
+}
+
+// Close shuts the gateway down
+func (g *Gateway) Close() {
+	g.closeOnce.Do(func() { close(g.closed) })
+	g.server.Close()
+}
+
+// URL returns the base URL of the gateway
+func (g *Gateway) URL() string {
+	return g.server.URL
+}
+
+// SDK returns an SDK addressing the gateway. BaseURL and SecretKey are set
+// unless config sets them
+func (g *Gateway) SDK(config payriff.Config) *payriff.SDK {
+	if config.BaseURL == "" {
+		config.BaseURL = g.URL()
+	}
+	if config.SecretKey == "" {
+		config.SecretKey = SecretKey
+	}
+	return payriff.NewSDK(config)
+}
+
+// Order returns the gateway's view of an order
+func (g *Gateway) Order(id payriff.OrderID) (payriff.OrderInfo, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	o, ok := g.ledger.get(id)
+	if !ok {
+		return payriff.OrderInfo{}, false
+	}
+	g.expire(o)
+	return o.info, true
+}
+
+// LastOrder returns the ID of the most recently created order
+func (g *Gateway) LastOrder() (payriff.OrderID, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.ledger.last()
+}
+
+// SetStatus changes an order's status as the shopper or bank would,
+// recording a transaction
+func (g *Gateway) SetStatus(id payriff.OrderID, status payriff.Status) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	o, ok := g.ledger.get(id)
+	if !ok {
+		return fmt.Errorf("payrifftest: unknown order %s", id)
+	}
+	g.ledger.setStatus(o, status)
+	return nil
+}
+
+// CallbackRequest builds the callback the gateway sends for an order's
+// current state, addressed to the order's callback URL
+func (g *Gateway) CallbackRequest(ctx context.Context, id payriff.OrderID) (*http.Request, error) {
+	info, ok := g.Order(id)
+	if !ok {
+		return nil, fmt.Errorf("payrifftest: unknown order %s", id)
+	}
+	body, err := json.Marshal(envelope(info))
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	o, _ := g.ledger.get(id)
+	target := o.callbackURL
+	g.mu.Unlock()
+	if target == "" {
+		target = "http://merchant.invalid/callback"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.CallbackSecret != "" {
+		req.Header.Set(payriff.HeaderCallbackSignature, payriff.NewCallbackSignature(g.CallbackSecret).Sign(body))
+	}
+	return req, nil
+}
+
+func (g *Gateway) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "" {
+		writeJSON(w, failure(payriff.ResultCodeUnauthorized, "unauthorized"))
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	outcome := g.next(scriptPath(path))
+	if outcome == OutcomeTimeout {
+		g.hang(w, r)
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && path == "/orders":
+		var req payriff.CreateOrderRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		writeJSON(w, envelope(g.createOrder(req, outcome)))
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/orders/"):
+		o, ok := g.ledger.get(payriff.OrderID(strings.TrimPrefix(path, "/orders/")))
+		if !ok {
+			writeJSON(w, failure(payriff.ResultCodeInvalidParameters, "order not found"))
+			return
+		}
+		if outcome == OutcomeExpired && o.info.PaymentStatus == payriff.StatusCreated {
+			o.info.PaymentStatus = payriff.StatusExpired
+		}
+		g.expire(o)
+		writeJSON(w, envelope(o.info))
+	case r.Method == http.MethodPost && path == "/refund":
+		var req payriff.RefundRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		if resp, failed := g.fail(req.OrderID, outcome); failed {
+			writeJSON(w, resp)
+			return
+		}
+		writeJSON(w, g.refund(req))
+	case r.Method == http.MethodPost && path == "/complete":
+		var req payriff.CompleteRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		if resp, failed := g.fail(req.OrderID, outcome); failed {
+			writeJSON(w, resp)
+			return
+		}
+		writeJSON(w, g.transition(req.OrderID, payriff.StatusPreAuthApproved, payriff.StatusApproved))
+	case r.Method == http.MethodPost && path == "/reverse":
+		var req payriff.ReverseRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		if resp, failed := g.fail(req.OrderID, outcome); failed {
+			writeJSON(w, resp)
+			return
+		}
+		writeJSON(w, g.transition(req.OrderID, payriff.StatusPreAuthApproved, payriff.StatusReverse))
+	case r.Method == http.MethodPost && path == "/autoPay":
+		var req payriff.AutoPayRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		writeJSON(w, envelope(g.autoPay(req, outcome)))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(w, failure(payriff.ResultCodeError, "not found"))
+	}
+}
+
+// createOrder registers a new order and applies a scripted outcome as if
+// the shopper had paid right away. Callers hold g.mu
+func (g *Gateway) createOrder(req payriff.CreateOrderRequest, outcome Outcome) payriff.OrderPayload {
+	o := g.ledger.create(req)
+	switch outcome {
+	case OutcomeApproved:
+		g.ledger.setStatus(o, paidStatus(req.Operation))
+	case OutcomeDeclined:
+		g.ledger.setStatus(o, payriff.StatusDeclined)
+	case OutcomeExpired:
+		o.info.PaymentStatus = payriff.StatusExpired
+	}
+	return payriff.OrderPayload{OrderID: o.info.OrderID, PaymentURL: g.URL() + "/pay/" + string(o.info.OrderID)}
+}
+
+// autoPay charges a saved card. Any card UUID is accepted. Callers hold g.mu
+func (g *Gateway) autoPay(req payriff.AutoPayRequest, outcome Outcome) payriff.AutoPayResult {
+	o := g.ledger.autoPay(req)
+	var code, message string
+	switch outcome {
+	case OutcomeDeclined:
+		g.ledger.setStatus(o, payriff.StatusDeclined)
+		code, message = DeclineDoNotHonor, "Do not honor"
+	case OutcomeExpired:
+		g.ledger.setStatus(o, payriff.StatusDeclined)
+		code, message = DeclineExpiredCard, "Expired card"
+	default:
+		g.ledger.setStatus(o, paidStatus(o.info.OperationType))
+	}
+
+	result := autoPayResult(o)
+	result.ResponseCode, result.ResponseMessage = code, message
+	return result
+}
+
+// fail applies a declined or expired outcome to a call on an existing
+// order, reporting whether the call failed. Callers hold g.mu
+func (g *Gateway) fail(id payriff.OrderID, outcome Outcome) (any, bool) {
+	switch outcome {
+	case OutcomeDeclined:
+		return failure(payriff.ResultCodeError, "declined by bank"), true
+	case OutcomeExpired:
+		if o, ok := g.ledger.get(id); ok {
+			o.info.PaymentStatus = payriff.StatusExpired
+		}
+		return failure(payriff.ResultCodeError, fmt.Sprintf("order is %s", payriff.StatusExpired)), true
+	}
+	return nil, false
+}
+
+// refund applies a full or partial refund. Callers hold g.mu
+func (g *Gateway) refund(req payriff.RefundRequest) any {
+	o, code, message := g.ledger.refund(req)
+	if o == nil {
+		return failure(code, message)
+	}
+	return envelope(o.info)
+}
+
+// transition moves an order from one status to another. Callers hold g.mu
+func (g *Gateway) transition(id payriff.OrderID, from, to payriff.Status) any {
+	o, code, message := g.ledger.transition(id, from, to)
+	if o == nil {
+		return failure(code, message)
+	}
+	return envelope(o.info)
+}
+
+// expire marks unpaid orders older than OrderTTL as expired. Callers hold g.mu
+func (g *Gateway) expire(o *order) {
+	ttl := g.OrderTTL
+	if ttl <= 0 {
+		ttl = 30 * time.Minute
+	}
+	if o.info.PaymentStatus == payriff.StatusCreated && !g.Clock.Now().Before(o.createdAt.Add(ttl)) {
+		o.info.PaymentStatus = payriff.StatusExpired
+	}
+}
+
+func envelope(payload any) map[string]any {
+	return map[string]any{"code": payriff.ResultCodeSuccess, "message": "OK", "payload": payload}
+}
+
+func failure(code payriff.ResultCode, message string) map[string]any {
+	return map[string]any{"code": code, "message": message}
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeJSON(w, failure(payriff.ResultCodeInvalidParameters, err.Error()))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
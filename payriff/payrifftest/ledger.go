@@ -0,0 +1,151 @@
+package payrifftest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kerimovok/payriff-sdk-go/payriff"
+)
+
+// ledger is the in-memory order book shared by Gateway and FakeClient, so
+// both simulate the same lifecycle. Callers hold their own lock
+type ledger struct {
+	// prefix starts every order ID, e.g. ORD-0001
+	prefix string
+	now    func() time.Time
+
+	orders map[payriff.OrderID]*order
+	// created lists order IDs in creation order
+	created []payriff.OrderID
+}
+
+type order struct {
+	info        payriff.OrderInfo
+	callbackURL string
+	createdAt   time.Time
+	refunded    payriff.Amount
+}
+
+// get returns an order by ID
+func (l *ledger) get(id payriff.OrderID) (*order, bool) {
+	o, ok := l.orders[id]
+	return o, ok
+}
+
+// last returns the ID of the most recently created order
+func (l *ledger) last() (payriff.OrderID, bool) {
+	if len(l.created) == 0 {
+		return "", false
+	}
+	return l.created[len(l.created)-1], true
+}
+
+// create registers an unpaid order
+func (l *ledger) create(req payriff.CreateOrderRequest) *order {
+	if l.orders == nil {
+		l.orders = make(map[payriff.OrderID]*order)
+	}
+	if req.Currency == "" {
+		req.Currency = payriff.CurrencyAZN
+	}
+	if req.Operation == "" {
+		req.Operation = payriff.OperationPurchase
+	}
+
+	now := l.now()
+	id := payriff.OrderID(fmt.Sprintf("%s-%04d", l.prefix, len(l.created)+1))
+	o := &order{
+		info: payriff.OrderInfo{
+			OrderID:       id,
+			Amount:        req.Amount.Float64(),
+			CurrencyType:  req.Currency,
+			MerchantName:  "payrifftest",
+			OperationType: req.Operation,
+			PaymentStatus: payriff.StatusCreated,
+			CreatedDate:   now.Format(time.RFC3339),
+			Description:   req.Description,
+			TerminalID:    req.TerminalID,
+			Customer:      req.Customer,
+		},
+		callbackURL: req.CallbackURL,
+		createdAt:   now,
+	}
+	l.orders[id] = o
+	l.created = append(l.created, id)
+	return o
+}
+
+// autoPay registers an order for a saved-card charge, not yet settled
+func (l *ledger) autoPay(req payriff.AutoPayRequest) *order {
+	return l.create(payriff.CreateOrderRequest{
+		Amount:      req.Amount,
+		Description: req.Description,
+		Operation:   req.Operation,
+		Currency:    req.Currency,
+		CallbackURL: req.CallbackURL,
+		Installment: req.Installment,
+	})
+}
+
+// setStatus records a status change with a transaction
+func (l *ledger) setStatus(o *order, status payriff.Status) {
+	o.info.PaymentStatus = status
+	o.info.Transactions = append(o.info.Transactions, payriff.Transaction{
+		UUID:        fmt.Sprintf("%s-TX-%d", o.info.OrderID, len(o.info.Transactions)+1),
+		CreatedDate: l.now().Format(time.RFC3339),
+		Status:      status,
+	})
+}
+
+// transition moves an order from one status to another, returning a nil
+// order and the failure otherwise
+func (l *ledger) transition(id payriff.OrderID, from, to payriff.Status) (*order, payriff.ResultCode, string) {
+	o, ok := l.orders[id]
+	if !ok {
+		return nil, payriff.ResultCodeInvalidParameters, "order not found"
+	}
+	if o.info.PaymentStatus != from {
+		return nil, payriff.ResultCodeError, fmt.Sprintf("order is %s", o.info.PaymentStatus)
+	}
+	l.setStatus(o, to)
+	return o, "", ""
+}
+
+// refund applies a full or partial refund, returning a nil order and the
+// failure when the order cannot be refunded by that amount
+func (l *ledger) refund(req payriff.RefundRequest) (*order, payriff.ResultCode, string) {
+	o, ok := l.orders[req.OrderID]
+	if !ok {
+		return nil, payriff.ResultCodeInvalidParameters, "order not found"
+	}
+	if s := o.info.PaymentStatus; !s.IsRefundable() {
+		return nil, payriff.ResultCodeError, fmt.Sprintf("order is %s", s)
+	}
+
+	total := payriff.AmountOf(o.info.Amount)
+	refunded := o.refunded.Add(req.Amount)
+	if !req.Amount.IsPositive() || refunded.Cmp(total) > 0 {
+		return nil, payriff.ResultCodeInvalidParameters, "invalid refund amount"
+	}
+	o.refunded = refunded
+	if refunded.Cmp(total) == 0 {
+		l.setStatus(o, payriff.StatusRefunded)
+	} else {
+		l.setStatus(o, payriff.StatusPartialRefund)
+	}
+	return o, "", ""
+}
+
+// autoPayResult describes a settled saved-card charge
+func autoPayResult(o *order) payriff.AutoPayResult {
+	return payriff.AutoPayResult{
+		OrderID:       o.info.OrderID,
+		Amount:        o.info.Amount,
+		CurrencyType:  o.info.CurrencyType,
+		OperationType: o.info.OperationType,
+		PaymentStatus: o.info.PaymentStatus,
+		Description:   o.info.Description,
+		CreatedDate:   o.info.CreatedDate,
+		Transactions:  o.info.Transactions,
+	}
+}
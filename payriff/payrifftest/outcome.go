@@ -0,0 +1,95 @@
+package payrifftest
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kerimovok/payriff-sdk-go/payriff"
+)
+
+// Outcome scripts how the gateway answers one call
+type Outcome int
+
+const (
+	// OutcomeApproved processes the call successfully. Created orders are
+	// paid at once, becoming APPROVED or PREAUTH_APPROVED
+	OutcomeApproved Outcome = iota + 1
+	// OutcomeDeclined has the bank decline: created orders and automatic
+	// payments become DECLINED, and refunds, completions and reversals fail
+	OutcomeDeclined
+	// OutcomeExpired expires the order: created orders become EXPIRED,
+	// automatic payments are declined for an expired card, and calls on an
+	// existing order fail because it expired
+	OutcomeExpired
+	// OutcomeTimeout answers nothing until the client gives up or
+	// Gateway.Timeout passes, then responds with 504
+	OutcomeTimeout
+)
+
+// Decline codes reported on automatic payments declined by a script
+const (
+	DeclineDoNotHonor  = "05"
+	DeclineExpiredCard = "54"
+)
+
+// Script queues outcomes for the next calls to path, e.g. /orders or
+// /autoPay, one outcome per call. Unscripted calls are processed normally,
+// leaving created orders unpaid
+func (g *Gateway) Script(path string, outcomes ...Outcome) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.scripts == nil {
+		g.scripts = make(map[string][]Outcome)
+	}
+	g.scripts[path] = append(g.scripts[path], outcomes...)
+}
+
+// next pops the scripted outcome of a call to path, 0 when none is queued
+func (g *Gateway) next(path string) Outcome {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	queue := g.scripts[path]
+	if len(queue) == 0 {
+		return 0
+	}
+	g.scripts[path] = queue[1:]
+	return queue[0]
+}
+
+// hang holds a request until the client disconnects, the timeout passes or
+// the gateway closes
+func (g *Gateway) hang(w http.ResponseWriter, r *http.Request) {
+	timeout := g.Timeout
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-r.Context().Done():
+		return
+	case <-g.closed:
+	case <-timer.C:
+	}
+	w.WriteHeader(http.StatusGatewayTimeout)
+}
+
+// scriptPath returns the path an outcome is scripted under
+func scriptPath(path string) string {
+	if strings.HasPrefix(path, "/orders/") {
+		return "/orders/{orderId}"
+	}
+	return path
+}
+
+// paidStatus is the status a paid order of operation reaches
+func paidStatus(operation payriff.Operation) payriff.Status {
+	if operation.IsPreAuth() {
+		return payriff.StatusPreAuthApproved
+	}
+	return payriff.StatusApproved
+}
@@ -0,0 +1,235 @@
+package payrifftest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kerimovok/payriff-sdk-go/payriff"
+)
+
+// Env is the world a scenario runs in
+type Env struct {
+	T       testing.TB
+	Ctx     context.Context
+	Clock   *Clock
+	Gateway *Gateway
+	SDK     *payriff.SDK
+	// Handler receives callbacks, typically the merchant's webhook handler.
+	// When nil, callbacks are posted to the order's callback URL
+	Handler http.Handler
+
+	orders map[string]payriff.OrderID
+}
+
+// Order returns the order ID bound to alias, failing the test when unbound
+func (e *Env) Order(alias string) payriff.OrderID {
+	id, ok := e.orders[alias]
+	if !ok {
+		e.T.Fatalf("payrifftest: no order bound to %q", alias)
+	}
+	return id
+}
+
+// Bind names an order so later steps can refer to it
+func (e *Env) Bind(alias string, id payriff.OrderID) {
+	e.orders[alias] = id
+}
+
+// Step is one action or assertion of a scenario
+type Step struct {
+	Name string
+	Run  func(e *Env) error
+}
+
+// Scenario is a readable sequence of payment events and assertions, e.g.
+// create → callback approved → partial refund → expect status
+type Scenario struct {
+	Name  string
+	Steps []Step
+	// Start is the initial clock time, defaults to the current time
+	Start time.Time
+}
+
+// NewScenario starts an empty scenario
+func NewScenario(name string) *Scenario {
+	return &Scenario{Name: name}
+}
+
+// Do runs merchant code, e.g. a checkout handler
+func (s *Scenario) Do(name string, fn func(e *Env) error) *Scenario {
+	s.Steps = append(s.Steps, Step{Name: name, Run: fn})
+	return s
+}
+
+// CreateOrder creates an order through the SDK and binds it to alias
+func (s *Scenario) CreateOrder(alias string, req payriff.CreateOrderRequest) *Scenario {
+	return s.Do("create order "+alias, func(e *Env) error {
+		resp, err := e.SDK.CreateOrderContext(e.Ctx, req)
+		if err != nil {
+			return err
+		}
+		if err := resp.Err(); err != nil {
+			return fmt.Errorf("order rejected: %w", err)
+		}
+		e.Bind(alias, resp.Payload.OrderID)
+		return nil
+	})
+}
+
+// BindLast binds alias to the order created most recently at the gateway,
+// e.g. by merchant code run with Do
+func (s *Scenario) BindLast(alias string) *Scenario {
+	return s.Do("bind "+alias, func(e *Env) error {
+		id, ok := e.Gateway.LastOrder()
+		if !ok {
+			return fmt.Errorf("no order was created")
+		}
+		e.Bind(alias, id)
+		return nil
+	})
+}
+
+// Callback moves the order to status and delivers the gateway callback,
+// failing unless the merchant answers with a 2xx status
+func (s *Scenario) Callback(alias string, status payriff.Status) *Scenario {
+	return s.Do(fmt.Sprintf("callback %s %s", alias, status), func(e *Env) error {
+		id := e.Order(alias)
+		if err := e.Gateway.SetStatus(id, status); err != nil {
+			return err
+		}
+		return e.deliver(id)
+	})
+}
+
+// Approve is Callback with StatusApproved
+func (s *Scenario) Approve(alias string) *Scenario {
+	return s.Callback(alias, payriff.StatusApproved)
+}
+
+// Decline is Callback with StatusDeclined
+func (s *Scenario) Decline(alias string) *Scenario {
+	return s.Callback(alias, payriff.StatusDeclined)
+}
+
+// Redeliver sends the order's callback again without changing it
+func (s *Scenario) Redeliver(alias string) *Scenario {
+	return s.Do("redeliver "+alias, func(e *Env) error {
+		return e.deliver(e.Order(alias))
+	})
+}
+
+// Refund refunds amount of the order through the SDK
+func (s *Scenario) Refund(alias string, amount payriff.Amount) *Scenario {
+	return s.Do(fmt.Sprintf("refund %s %s", alias, amount), func(e *Env) error {
+		resp, err := e.SDK.RefundContext(e.Ctx, payriff.RefundRequest{OrderID: e.Order(alias), Amount: amount})
+		if err != nil {
+			return err
+		}
+		if err := resp.Err(); err != nil {
+			return fmt.Errorf("refund rejected: %w", err)
+		}
+		return nil
+	})
+}
+
+// Script queues outcomes for the next calls to path, see Gateway.Script
+func (s *Scenario) Script(path string, outcomes ...Outcome) *Scenario {
+	return s.Do("script "+path, func(e *Env) error {
+		e.Gateway.Script(path, outcomes...)
+		return nil
+	})
+}
+
+// Advance moves the clock forward, e.g. past order expiry
+func (s *Scenario) Advance(d time.Duration) *Scenario {
+	return s.Do(fmt.Sprintf("advance %s", d), func(e *Env) error {
+		e.Clock.Advance(d)
+		return nil
+	})
+}
+
+// Expect asserts the merchant's observable behavior
+func (s *Scenario) Expect(name string, fn func(e *Env) error) *Scenario {
+	return s.Do("expect "+name, fn)
+}
+
+// ExpectStatus asserts the order status reported by GetOrderInfo
+func (s *Scenario) ExpectStatus(alias string, status payriff.Status) *Scenario {
+	return s.Expect(fmt.Sprintf("%s is %s", alias, status), func(e *Env) error {
+		resp, err := e.SDK.GetOrderInfoContext(e.Ctx, e.Order(alias))
+		if err != nil {
+			return err
+		}
+		if resp.Payload.PaymentStatus != status {
+			return fmt.Errorf("order %s is %s, want %s", resp.Payload.OrderID, resp.Payload.PaymentStatus, status)
+		}
+		return nil
+	})
+}
+
+// Run executes the scenario against a fresh gateway. setup wires the
+// merchant code into the environment, e.g. by setting Handler; it may be
+// nil. The first failing step fails the test
+func (s *Scenario) Run(t testing.TB, setup func(e *Env)) {
+	t.Helper()
+
+	start := s.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+	clock := NewClock(start)
+	gateway := NewGateway(clock)
+	defer gateway.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := &Env{
+		T:       t,
+		Ctx:     ctx,
+		Clock:   clock,
+		Gateway: gateway,
+		SDK:     gateway.SDK(payriff.Config{}),
+		orders:  make(map[string]payriff.OrderID),
+	}
+	if setup != nil {
+		setup(e)
+	}
+
+	for i, step := range s.Steps {
+		if err := step.Run(e); err != nil {
+			t.Fatalf("scenario %q, step %d (%s): %v", s.Name, i+1, step.Name, err)
+		}
+	}
+}
+
+// deliver sends the order's callback to Handler or its callback URL
+func (e *Env) deliver(id payriff.OrderID) error {
+	req, err := e.Gateway.CallbackRequest(e.Ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var status int
+	if e.Handler != nil {
+		rec := httptest.NewRecorder()
+		e.Handler.ServeHTTP(rec, req)
+		status = rec.Code
+	} else {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to deliver callback: %w", err)
+		}
+		resp.Body.Close()
+		status = resp.StatusCode
+	}
+
+	if status < 200 || status > 299 {
+		return fmt.Errorf("merchant answered callback for %s with %d", id, status)
+	}
+	return nil
+}
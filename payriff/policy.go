@@ -0,0 +1,280 @@
+package payriff
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrPolicyViolation matches every *PolicyViolation
+	ErrPolicyViolation = errors.New("payriff: amount policy violated")
+	// ErrInvalidOverride is returned for forged or expired override tokens
+	ErrInvalidOverride = errors.New("payriff: invalid policy override token")
+)
+
+// PolicyRule names an amount limit
+type PolicyRule string
+
+const (
+	RuleMaxCharge PolicyRule = "max_charge"
+	RuleMaxRefund PolicyRule = "max_refund"
+	RuleDailyCap  PolicyRule = "daily_cap"
+)
+
+// PolicyViolation reports a call blocked by the amount policy
+type PolicyViolation struct {
+	Rule     PolicyRule
+	Currency Currency
+	Limit    Amount
+	Amount   Amount
+}
+
+func (v *PolicyViolation) Error() string {
+	return fmt.Sprintf("payriff: %s %s exceeds %s limit of %s", v.Amount, v.Currency, v.Rule, v.Limit)
+}
+
+func (v *PolicyViolation) Unwrap() error {
+	return ErrPolicyViolation
+}
+
+// AmountLimits are the limits for one currency. Zero means unlimited
+type AmountLimits struct {
+	// MaxCharge caps a single order or AutoPay charge
+	MaxCharge Amount
+	// MaxRefund caps refunds made without RefundApprovals
+	MaxRefund Amount
+	// DailyCap caps the total of orders and AutoPay charges per calendar day
+	DailyCap Amount
+}
+
+// AmountPolicy enforces amount limits client-side before gateway calls.
+// Refunds are checked against the limits of the refunded order's currency
+type AmountPolicy struct {
+	Limits map[Currency]AmountLimits
+	// Overrides verifies tokens that let authorized staff exceed the limits
+	Overrides PolicyOverrideVerifier
+
+	mu    sync.Mutex
+	day   string
+	daily map[Currency]Amount
+}
+
+// OverrideScope is the call an override token allows: the rule it exceeds,
+// the currency and amount of the call and, for refunds, the order
+type OverrideScope struct {
+	Rule     PolicyRule
+	Currency Currency
+	Amount   Amount
+	OrderID  OrderID
+}
+
+// PolicyOverrideVerifier checks that an override token was issued for scope
+// and returns the operator who issued it
+type PolicyOverrideVerifier interface {
+	VerifyOverride(ctx context.Context, token string, scope OverrideScope) (string, error)
+}
+
+type policyOverrideCtx struct{}
+
+// WithPolicyOverride returns a context whose calls may exceed the amount
+// policy when one of tokens verifies for the exceeded rule
+func WithPolicyOverride(ctx context.Context, tokens ...string) context.Context {
+	return context.WithValue(ctx, policyOverrideCtx{}, tokens)
+}
+
+type overriddenByCtx struct{}
+
+// withOverriddenBy records the operator whose override token allowed a call,
+// for the audit record
+func withOverriddenBy(ctx context.Context, operator string) context.Context {
+	if operator == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, overriddenByCtx{}, operator)
+}
+
+// overriddenBy returns the operator set with withOverriddenBy
+func overriddenBy(ctx context.Context) string {
+	operator, _ := ctx.Value(overriddenByCtx{}).(string)
+	return operator
+}
+
+type approvedRefundCtx struct{}
+
+// override returns the operator of an override token on ctx that verifies
+// for the violation, or the violation
+func (p *AmountPolicy) override(ctx context.Context, violation *PolicyViolation, amount Amount, orderID OrderID) (string, error) {
+	tokens, _ := ctx.Value(policyOverrideCtx{}).([]string)
+	if len(tokens) == 0 || p.Overrides == nil {
+		return "", violation
+	}
+
+	scope := OverrideScope{Rule: violation.Rule, Currency: violation.Currency, Amount: amount, OrderID: orderID}
+	var errs []error
+	for _, token := range tokens {
+		operator, err := p.Overrides.VerifyOverride(ctx, token, scope)
+		if err == nil {
+			return operator, nil
+		}
+		errs = append(errs, err)
+	}
+	return "", fmt.Errorf("%w: %w", violation, errors.Join(errs...))
+}
+
+// limitsRefunds reports whether any currency has a refund limit
+func (p *AmountPolicy) limitsRefunds() bool {
+	if p == nil {
+		return false
+	}
+	for _, limits := range p.Limits {
+		if limits.MaxRefund.IsPositive() {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRefund returns a *PolicyViolation unless a refund of amount from
+// order fits MaxRefund or ctx carries a valid override. It returns the
+// operator of the override used
+func (p *AmountPolicy) checkRefund(ctx context.Context, currency Currency, orderID OrderID, amount Amount) (string, error) {
+	if p == nil || ctx.Value(approvedRefundCtx{}) != nil {
+		return "", nil
+	}
+
+	limit := p.Limits[currency].MaxRefund
+	if !limit.IsPositive() || amount.Cmp(limit) <= 0 {
+		return "", nil
+	}
+	return p.override(ctx, &PolicyViolation{Rule: RuleMaxRefund, Currency: currency, Limit: limit, Amount: amount}, amount, orderID)
+}
+
+// charge checks a charge against MaxCharge and reserves it against the
+// daily cap in one step, so concurrent charges cannot both fit the last of
+// the cap. The returned release undoes the reservation when the charge
+// fails. It also returns the operator of the override used
+func (p *AmountPolicy) charge(ctx context.Context, currency Currency, amount Amount) (string, func(), error) {
+	if p == nil {
+		return "", func() {}, nil
+	}
+
+	limits := p.Limits[currency]
+	var operator string
+	if limits.MaxCharge.IsPositive() && amount.Cmp(limits.MaxCharge) > 0 {
+		violation := &PolicyViolation{Rule: RuleMaxCharge, Currency: currency, Limit: limits.MaxCharge, Amount: amount}
+		op, err := p.override(ctx, violation, amount, "")
+		if err != nil {
+			return "", nil, err
+		}
+		operator = op
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rollover()
+	if total := p.daily[currency].Add(amount); limits.DailyCap.IsPositive() && total.Cmp(limits.DailyCap) > 0 {
+		// Verify without holding the lock, the verifier may be remote
+		p.mu.Unlock()
+		violation := &PolicyViolation{Rule: RuleDailyCap, Currency: currency, Limit: limits.DailyCap, Amount: total}
+		op, err := p.override(ctx, violation, amount, "")
+		p.mu.Lock()
+		if err != nil {
+			return "", nil, err
+		}
+		operator = op
+		p.rollover()
+	}
+	p.daily[currency] = p.daily[currency].Add(amount)
+
+	day := p.day
+	release := func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.day == day {
+			p.daily[currency] = p.daily[currency].Sub(amount)
+		}
+	}
+	return operator, release, nil
+}
+
+// rollover resets the daily totals at midnight. Callers hold p.mu
+func (p *AmountPolicy) rollover() {
+	today := time.Now().Format(time.DateOnly)
+	if p.day != today || p.daily == nil {
+		p.day = today
+		p.daily = make(map[Currency]Amount)
+	}
+}
+
+// OverrideTokens issues and verifies HMAC-signed policy override tokens
+type OverrideTokens struct {
+	Key []byte
+}
+
+// overrideClaims are the signed contents of an override token
+type overrideClaims struct {
+	Operator string     `json:"op"`
+	Rule     PolicyRule `json:"rule"`
+	Currency Currency   `json:"cur"`
+	Amount   int64      `json:"amt"`
+	OrderID  OrderID    `json:"order,omitempty"`
+	Expires  int64      `json:"exp"`
+}
+
+// Issue returns a token for operator that allows only the call described by
+// scope, valid for ttl. Charges have no OrderID in their scope
+func (o *OverrideTokens) Issue(operator string, scope OverrideScope, ttl time.Duration) string {
+	claims, _ := json.Marshal(overrideClaims{
+		Operator: operator,
+		Rule:     scope.Rule,
+		Currency: scope.Currency,
+		Amount:   scope.Amount.Minor(),
+		OrderID:  scope.OrderID,
+		Expires:  time.Now().Add(ttl).Unix(),
+	})
+	encoded := base64.RawURLEncoding.EncodeToString(claims)
+	return encoded + "." + o.sign(encoded)
+}
+
+// VerifyOverride implements PolicyOverrideVerifier
+func (o *OverrideTokens) VerifyOverride(ctx context.Context, token string, scope OverrideScope) (string, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(o.sign(encoded))) {
+		return "", ErrInvalidOverride
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidOverride, err)
+	}
+	var claims overrideClaims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidOverride, err)
+	}
+	if time.Now().After(time.Unix(claims.Expires, 0)) {
+		return "", fmt.Errorf("%w: expired", ErrInvalidOverride)
+	}
+	if claims.Rule != scope.Rule || claims.Currency != scope.Currency ||
+		claims.Amount != scope.Amount.Minor() || claims.OrderID != scope.OrderID {
+		return "", fmt.Errorf("%w: issued for %s %s %s, not this call", ErrInvalidOverride, claims.Rule, MinorUnits(claims.Amount), claims.Currency)
+	}
+	if claims.Operator == "" {
+		return "", fmt.Errorf("%w: no operator", ErrInvalidOverride)
+	}
+	return claims.Operator, nil
+}
+
+func (o *OverrideTokens) sign(encoded string) string {
+	mac := hmac.New(sha256.New, o.Key)
+	mac.Write([]byte(encoded))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,308 @@
+package payriff
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxDeliveryBody caps the size of callback bodies read by a Processor.
+// Larger bodies are answered with 413 instead of being truncated
+const maxDeliveryBody = 1 << 20
+
+var (
+	// ErrDeliveryRejected is returned when a delivery fails verification
+	ErrDeliveryRejected = errors.New("payriff: delivery rejected")
+	// ErrVerificationUnavailable is wrapped by verifiers that could not
+	// decide, e.g. because the gateway or a store failed. Such deliveries
+	// are retried instead of rejected
+	ErrVerificationUnavailable = errors.New("payriff: delivery verification unavailable")
+	// ErrDuplicateDelivery is returned by an EventStore for keys already committed
+	ErrDuplicateDelivery = errors.New("payriff: duplicate delivery")
+	// ErrDeliveryInProgress is returned by an EventStore for keys claimed by another worker
+	ErrDeliveryInProgress = errors.New("payriff: delivery in progress")
+)
+
+// Delivery is a single callback request received from the gateway
+type Delivery struct {
+	Body       []byte
+	Header     http.Header
+	RemoteAddr string
+	ReceivedAt time.Time
+
+	// Attempt counts how many times the gateway has sent this delivery,
+	// starting at 1. It is set by the EventStore when the delivery is claimed
+	Attempt int
+	// FirstReceivedAt is when the first attempt of this delivery arrived
+	FirstReceivedAt time.Time
+}
+
+// Redelivery reports whether the gateway sent this delivery before, because
+// an earlier attempt failed or was not acknowledged in time
+func (d *Delivery) Redelivery() bool {
+	return d.Attempt > 1
+}
+
+// DeliveryVerifier checks that a delivery is authentic before it is processed
+type DeliveryVerifier interface {
+	VerifyDelivery(ctx context.Context, d *Delivery) error
+}
+
+// DeliveryVerifierFunc adapts a function to the DeliveryVerifier interface
+type DeliveryVerifierFunc func(ctx context.Context, d *Delivery) error
+
+// VerifyDelivery calls f(ctx, d)
+func (f DeliveryVerifierFunc) VerifyDelivery(ctx context.Context, d *Delivery) error {
+	return f(ctx, d)
+}
+
+// DeliveryHandler processes a verified delivery
+type DeliveryHandler func(ctx context.Context, d *Delivery) error
+
+// EventStore persists deliveries and tracks their processing state
+type EventStore interface {
+	// Claim persists the delivery and marks key as in progress. It returns
+	// ErrDuplicateDelivery when key was already committed and
+	// ErrDeliveryInProgress when another worker holds the claim. Stores that
+	// count receipts set d.Attempt and d.FirstReceivedAt
+	Claim(ctx context.Context, key string, d *Delivery) error
+	// Commit marks key as processed
+	Commit(ctx context.Context, key string) error
+	// Fail records a failed attempt and releases the claim so a redelivery
+	// can try again, returning the number of failed attempts so far
+	Fail(ctx context.Context, key string, cause error) (int, error)
+}
+
+// Processor combines verification, deduplication, persistence and handler
+// execution for callback deliveries. Handlers run at least once per
+// distinct delivery and a delivery is committed only after its handler
+// succeeds, so redeliveries of committed events are acknowledged without
+// running the handler again
+type Processor struct {
+	Verifiers []DeliveryVerifier
+	Store     EventStore
+	Handler   DeliveryHandler
+	// Key derives the deduplication key, defaults to the SHA-256 of the body
+	Key func(d *Delivery) string
+	// DeadLetters receives deliveries whose handler failed MaxAttempts times
+	DeadLetters DeadLetterQueue
+	// MaxAttempts is the number of failed attempts before a delivery is
+	// parked, defaults to 5 when DeadLetters is set
+	MaxAttempts int
+	// OnError receives panics recovered from verifiers and the handler
+	OnError func(error)
+}
+
+// Process verifies, deduplicates and handles a delivery. Duplicates of
+// committed deliveries return nil
+func (p *Processor) Process(ctx context.Context, d *Delivery) error {
+	for _, v := range p.Verifiers {
+		err := p.safeCall(func() error { return v.VerifyDelivery(ctx, d) })
+		var panicErr *PanicError
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrVerificationUnavailable), errors.As(err, &panicErr), ctx.Err() != nil:
+			return fmt.Errorf("failed to verify delivery: %w", err)
+		default:
+			return fmt.Errorf("%w: %w", ErrDeliveryRejected, err)
+		}
+	}
+
+	key := p.key(d)
+	if err := p.Store.Claim(ctx, key, d); err != nil {
+		if errors.Is(err, ErrDuplicateDelivery) {
+			return nil
+		}
+		return fmt.Errorf("failed to claim delivery %s: %w", key, err)
+	}
+	if d.Attempt == 0 {
+		d.Attempt = 1
+	}
+	if d.FirstReceivedAt.IsZero() {
+		d.FirstReceivedAt = d.ReceivedAt
+	}
+
+	if err := p.safeCall(func() error { return p.Handler(ctx, d) }); err != nil {
+		err = fmt.Errorf("delivery handler failed: %w", err)
+		attempts, ferr := p.Store.Fail(ctx, key, err)
+		if ferr != nil {
+			return errors.Join(err, fmt.Errorf("failed to release delivery %s: %w", key, ferr))
+		}
+		if p.DeadLetters != nil && attempts >= p.maxAttempts() {
+			return p.park(ctx, key, d, attempts, err)
+		}
+		return err
+	}
+
+	if err := p.Store.Commit(ctx, key); err != nil {
+		return fmt.Errorf("failed to commit delivery %s: %w", key, err)
+	}
+	return nil
+}
+
+// ServeHTTP processes a callback request. Committed deliveries and
+// duplicates of them are acknowledged with 200 and a success envelope, which
+// stops the gateway from redelivering. Any other status asks the gateway to
+// retry, so failed handlers and verifiers that could not decide run again on
+// the next attempt
+func (p *Processor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAck(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxDeliveryBody+1))
+	if err != nil {
+		writeAck(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+	if len(body) > maxDeliveryBody {
+		writeAck(w, http.StatusRequestEntityTooLarge, "body too large")
+		return
+	}
+
+	d := &Delivery{
+		Body:       body,
+		Header:     r.Header.Clone(),
+		RemoteAddr: r.RemoteAddr,
+		ReceivedAt: time.Now(),
+	}
+
+	switch err := p.Process(r.Context(), d); {
+	case err == nil:
+		writeAck(w, http.StatusOK, "OK")
+	case errors.Is(err, ErrDeliveryRejected):
+		writeAck(w, http.StatusUnauthorized, "delivery rejected")
+	case errors.Is(err, ErrDeliveryInProgress):
+		writeAck(w, http.StatusConflict, "delivery in progress")
+	case errors.Is(err, ErrVerificationUnavailable):
+		writeAck(w, http.StatusServiceUnavailable, "delivery verification unavailable")
+	default:
+		writeAck(w, http.StatusInternalServerError, "delivery failed")
+	}
+}
+
+// callbackAck is the body returned to the gateway for a delivery, in the
+// same envelope the gateway uses for its own responses
+type callbackAck struct {
+	Code    ResultCode `json:"code"`
+	Message string     `json:"message"`
+}
+
+// writeAck answers a delivery. Only 200 responses carry the success code
+func writeAck(w http.ResponseWriter, status int, message string) {
+	code := ResultCodeSuccess
+	if status != http.StatusOK {
+		code = ResultCode(fmt.Sprint(status))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(callbackAck{Code: code, Message: message})
+}
+
+// safeCall runs user code, reporting recovered panics through OnError
+func (p *Processor) safeCall(fn func() error) error {
+	err := safeCall(fn)
+	var panicErr *PanicError
+	if p.OnError != nil && errors.As(err, &panicErr) {
+		_ = safeCall(func() error {
+			p.OnError(err)
+			return nil
+		})
+	}
+	return err
+}
+
+func (p *Processor) maxAttempts() int {
+	if p.MaxAttempts > 0 {
+		return p.MaxAttempts
+	}
+	return 5
+}
+
+func (p *Processor) key(d *Delivery) string {
+	if p.Key != nil {
+		return p.Key(d)
+	}
+	sum := sha256.Sum256(d.Body)
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryEventStore is an in-process EventStore, suitable for single
+// instance deployments and tests
+type MemoryEventStore struct {
+	mu      sync.Mutex
+	entries map[string]*storedEvent
+}
+
+type storedEvent struct {
+	delivery  Delivery
+	received  int
+	claimed   bool
+	committed bool
+	failures  int
+	lastError error
+}
+
+// Claim implements EventStore
+func (m *MemoryEventStore) Claim(ctx context.Context, key string, d *Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entries == nil {
+		m.entries = make(map[string]*storedEvent)
+	}
+
+	e, ok := m.entries[key]
+	if !ok {
+		e = &storedEvent{delivery: *d}
+		m.entries[key] = e
+	}
+	e.received++
+	d.Attempt = e.received
+	d.FirstReceivedAt = e.delivery.ReceivedAt
+	switch {
+	case e.committed:
+		return ErrDuplicateDelivery
+	case e.claimed:
+		return ErrDeliveryInProgress
+	}
+	e.claimed = true
+	return nil
+}
+
+// Commit implements EventStore
+func (m *MemoryEventStore) Commit(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return fmt.Errorf("payriff: unknown delivery %s", key)
+	}
+	e.claimed = false
+	e.committed = true
+	return nil
+}
+
+// Fail implements EventStore
+func (m *MemoryEventStore) Fail(ctx context.Context, key string, cause error) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return 0, fmt.Errorf("payriff: unknown delivery %s", key)
+	}
+	e.claimed = false
+	e.failures++
+	e.lastError = cause
+	return e.failures, nil
+}
@@ -0,0 +1,57 @@
+package payriff
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownProfile is returned by API calls when the selected profile is
+// not defined in Config.Profiles
+var ErrUnknownProfile = errors.New("payriff: unknown profile")
+
+// Profile holds the settings of one environment such as dev, stage or prod
+type Profile struct {
+	Environment        Environment
+	BaseURL            string
+	SecretKey          string
+	PublicKey          string
+	Keys               []APIKey
+	DefaultCallbackURL string
+	DefaultLanguage    Language
+	DefaultCurrency    Currency
+}
+
+// applyProfile fills fields not set directly in config from the selected
+// profile
+func applyProfile(config *Config, name string) error {
+	p, ok := config.Profiles[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+	}
+
+	if config.Environment == "" {
+		config.Environment = p.Environment
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = p.BaseURL
+	}
+	if config.SecretKey == "" {
+		config.SecretKey = p.SecretKey
+	}
+	if config.PublicKey == "" {
+		config.PublicKey = p.PublicKey
+	}
+	if len(config.Keys) == 0 {
+		config.Keys = p.Keys
+	}
+	if config.DefaultCallbackURL == "" {
+		config.DefaultCallbackURL = p.DefaultCallbackURL
+	}
+	if config.DefaultLanguage == "" {
+		config.DefaultLanguage = p.DefaultLanguage
+	}
+	if config.DefaultCurrency == "" {
+		config.DefaultCurrency = p.DefaultCurrency
+	}
+	return nil
+}
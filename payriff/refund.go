@@ -0,0 +1,132 @@
+package payriff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrRefundExceedsOrder is returned for refunds above what is left of the
+// order amount after earlier refunds
+var ErrRefundExceedsOrder = errors.New("payriff: refund exceeds the remaining order amount")
+
+// RefundLedger tracks the amount refunded per order. The gateway does not
+// count refunds to another card against the order, so the SDK reserves
+// every refund here first. Share one ledger between SDK instances, e.g.
+// backed by your database, to enforce the limit across processes
+type RefundLedger interface {
+	// Reserve atomically adds amount to the order's refunded total, or
+	// returns an error matching ErrRefundExceedsOrder when the total would
+	// exceed limit. A non-positive limit records without a check
+	Reserve(ctx context.Context, orderID OrderID, amount, limit Amount) error
+	// Release takes back a reservation whose refund failed
+	Release(ctx context.Context, orderID OrderID, amount Amount) error
+}
+
+// MemoryRefundLedger is an in-process RefundLedger. The zero value is
+// ready to use
+type MemoryRefundLedger struct {
+	mu       sync.Mutex
+	refunded map[OrderID]Amount
+}
+
+// Reserve implements RefundLedger
+func (l *MemoryRefundLedger) Reserve(ctx context.Context, orderID OrderID, amount, limit Amount) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.refunded == nil {
+		l.refunded = make(map[OrderID]Amount)
+	}
+	total := l.refunded[orderID].Add(amount)
+	if limit.IsPositive() && total.Cmp(limit) > 0 {
+		return fmt.Errorf("%w: order %s has %s left", ErrRefundExceedsOrder, orderID, limit.Sub(l.refunded[orderID]))
+	}
+	l.refunded[orderID] = total
+	return nil
+}
+
+// Release implements RefundLedger
+func (l *MemoryRefundLedger) Release(ctx context.Context, orderID OrderID, amount Amount) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.refunded != nil {
+		l.refunded[orderID] = l.refunded[orderID].Sub(amount)
+	}
+	return nil
+}
+
+// Refunded returns the amount refunded for an order
+func (l *MemoryRefundLedger) Refunded(orderID OrderID) Amount {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.refunded[orderID]
+}
+
+// RefundDestination sends a refund to a card other than the one the order
+// was paid with, e.g. when the original card is closed. The refund is
+// made as a merchant-initiated transfer (Topup). Set either CardNumber or
+// CardUUID
+type RefundDestination struct {
+	CardNumber string
+	CardUUID   CardUUID
+}
+
+// refundToCard refunds an order by transferring the amount to another card
+func (s *SDK) refundToCard(ctx context.Context, req RefundRequest) (*ApiResponse[json.RawMessage], error) {
+	info, err := s.GetOrderInfoContext(ctx, req.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order %s for refund: %w", req.OrderID, err)
+	}
+	if err := info.Err(); err != nil {
+		return nil, fmt.Errorf("order %s not found for refund: %w", req.OrderID, err)
+	}
+	if status := info.Payload.PaymentStatus; !status.IsRefundable() {
+		return nil, fmt.Errorf("payriff: order %s cannot be refunded: %s", req.OrderID, status)
+	}
+	if !req.Amount.IsPositive() {
+		return nil, fmt.Errorf("payriff: refund amount must be positive, got %s", req.Amount)
+	}
+	if err := s.refundLedger.Reserve(ctx, req.OrderID, req.Amount, AmountOf(info.Payload.Amount)); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.Topup(ctx, TopupRequest{
+		CardNumber:  req.Destination.CardNumber,
+		CardUUID:    req.Destination.CardUUID,
+		Amount:      req.Amount,
+		Currency:    info.Payload.CurrencyType,
+		Description: fmt.Sprintf("Refund for order %s", req.OrderID),
+	})
+	if err != nil || !resp.IsSuccessful() {
+		s.releaseRefund(ctx, req.OrderID, req.Amount)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(resp.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode refund transfer payload: %w", err)
+	}
+	return &ApiResponse[json.RawMessage]{
+		Code:            resp.Code,
+		Message:         resp.Message,
+		Route:           resp.Route,
+		InternalMessage: resp.InternalMessage,
+		ResponseID:      resp.ResponseID,
+		Payload:         payload,
+	}, nil
+}
+
+// releaseRefund takes back a ledger reservation, reporting failures
+// through Hooks.OnError
+func (s *SDK) releaseRefund(ctx context.Context, orderID OrderID, amount Amount) {
+	if err := s.refundLedger.Release(ctx, orderID, amount); err != nil {
+		s.reportError(fmt.Errorf("failed to release refund of %s for order %s: %w", amount, orderID, err))
+	}
+}
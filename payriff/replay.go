@@ -0,0 +1,162 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Headers checked by a ReplayGuard by default
+const (
+	HeaderTimestamp = "X-Payriff-Timestamp"
+	HeaderNonce     = "X-Payriff-Nonce"
+)
+
+var (
+	// ErrStaleCallback is returned for callbacks outside the timestamp tolerance
+	ErrStaleCallback = errors.New("payriff: callback timestamp outside tolerance")
+	// ErrReplayedCallback is returned for callbacks whose nonce was already used
+	ErrReplayedCallback = errors.New("payriff: callback nonce already used")
+)
+
+// ReplayError describes a callback rejected by a ReplayGuard
+type ReplayError struct {
+	Nonce     string
+	Timestamp time.Time
+	Err       error
+}
+
+func (e *ReplayError) Error() string {
+	return fmt.Sprintf("%v (nonce %q, timestamp %s)", e.Err, e.Nonce, e.Timestamp.Format(time.RFC3339))
+}
+
+func (e *ReplayError) Unwrap() error {
+	return e.Err
+}
+
+// NonceStore remembers one-time nonces until they expire
+type NonceStore interface {
+	// Use records nonce and reports false if it was already used
+	Use(ctx context.Context, nonce string, expires time.Time) (bool, error)
+}
+
+// ReplayGuard is a DeliveryVerifier enforcing timestamp tolerance and
+// one-time nonces on incoming callbacks
+type ReplayGuard struct {
+	// Nonces stores used nonces, defaults to an in-memory store
+	Nonces NonceStore
+	// Tolerance is the accepted clock skew, defaults to five minutes
+	Tolerance time.Duration
+	// TimestampHeader and NonceHeader override the default header names
+	TimestampHeader string
+	NonceHeader     string
+	// OnReject is called for every rejected callback
+	OnReject func(err *ReplayError)
+
+	rejected atomic.Uint64
+	once     sync.Once
+}
+
+// Rejected returns the number of callbacks rejected so far
+func (g *ReplayGuard) Rejected() uint64 {
+	return g.rejected.Load()
+}
+
+// VerifyDelivery implements DeliveryVerifier
+func (g *ReplayGuard) VerifyDelivery(ctx context.Context, d *Delivery) error {
+	g.once.Do(func() {
+		if g.Nonces == nil {
+			g.Nonces = &MemoryNonceStore{}
+		}
+	})
+
+	rawTS := d.Header.Get(headerOr(g.TimestampHeader, HeaderTimestamp))
+	nonce := d.Header.Get(headerOr(g.NonceHeader, HeaderNonce))
+	if rawTS == "" || nonce == "" {
+		return g.reject(&ReplayError{Nonce: nonce, Err: fmt.Errorf("%w: missing timestamp or nonce", ErrStaleCallback)})
+	}
+
+	ts, err := parseTimestamp(rawTS)
+	if err != nil {
+		return g.reject(&ReplayError{Nonce: nonce, Err: fmt.Errorf("%w: %w", ErrStaleCallback, err)})
+	}
+
+	tolerance := g.Tolerance
+	if tolerance <= 0 {
+		tolerance = 5 * time.Minute
+	}
+	if age := time.Since(ts); age > tolerance || age < -tolerance {
+		return g.reject(&ReplayError{Nonce: nonce, Timestamp: ts, Err: ErrStaleCallback})
+	}
+
+	fresh, err := g.Nonces.Use(ctx, nonce, ts.Add(tolerance))
+	if err != nil {
+		return fmt.Errorf("%w: failed to record callback nonce: %w", ErrVerificationUnavailable, err)
+	}
+	if !fresh {
+		return g.reject(&ReplayError{Nonce: nonce, Timestamp: ts, Err: ErrReplayedCallback})
+	}
+	return nil
+}
+
+func (g *ReplayGuard) reject(err *ReplayError) error {
+	g.rejected.Add(1)
+	if g.OnReject != nil {
+		_ = safeCall(func() error {
+			g.OnReject(err)
+			return nil
+		})
+	}
+	return err
+}
+
+// MemoryNonceStore is an in-process NonceStore
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+// Use implements NonceStore
+func (m *MemoryNonceStore) Use(ctx context.Context, nonce string, expires time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if m.nonces == nil {
+		m.nonces = make(map[string]time.Time)
+	}
+	for n, exp := range m.nonces {
+		if now.After(exp) {
+			delete(m.nonces, n)
+		}
+	}
+
+	if _, ok := m.nonces[nonce]; ok {
+		return false, nil
+	}
+	m.nonces[nonce] = expires
+	return true, nil
+}
+
+// parseTimestamp accepts unix seconds or RFC 3339 timestamps
+func parseTimestamp(s string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	ts, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", s, err)
+	}
+	return ts, nil
+}
+
+func headerOr(name, fallback string) string {
+	if name != "" {
+		return name
+	}
+	return fallback
+}
@@ -0,0 +1,194 @@
+package payriff
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CurrencyTotals aggregates orders in one currency
+type CurrencyTotals struct {
+	Currency Currency
+	Orders   int
+	Amount   Amount
+}
+
+// SettlementTotals aggregates orders settled from one currency into another
+type SettlementTotals struct {
+	OrderCurrency      Currency
+	SettlementCurrency Currency
+	Orders             int
+	OrderAmount        Amount
+	SettledAmount      Amount
+}
+
+// EffectiveRate returns the average conversion rate across the orders
+func (t SettlementTotals) EffectiveRate() float64 {
+	if t.OrderAmount.IsZero() {
+		return 0
+	}
+	return float64(t.SettledAmount.Minor()) / float64(t.OrderAmount.Minor())
+}
+
+// TerminalTotals aggregates approved orders of one terminal or branch
+type TerminalTotals struct {
+	// TerminalID is empty for orders created without a terminal
+	TerminalID string
+	Currency   Currency
+	Orders     int
+	Amount     Amount
+}
+
+// Report summarizes a set of orders
+type Report struct {
+	Orders   int
+	ByStatus map[Status]int
+	// ByCurrency totals approved orders in their original currency
+	ByCurrency []CurrencyTotals
+	// Settlements totals approved orders by order and settlement currency,
+	// so FX differences can be reconciled
+	Settlements []SettlementTotals
+	// ByTerminal totals approved orders by terminal and currency
+	ByTerminal []TerminalTotals
+}
+
+// Summarize builds a Report from orders. Each order amount is converted to
+// minor units once, so totals are exact
+func Summarize(orders []OrderInfo) Report {
+	report := Report{Orders: len(orders), ByStatus: make(map[Status]int)}
+
+	byCurrency := make(map[Currency]*CurrencyTotals)
+	type pair struct{ from, to Currency }
+	settlements := make(map[pair]*SettlementTotals)
+	type terminal struct {
+		id       string
+		currency Currency
+	}
+	byTerminal := make(map[terminal]*TerminalTotals)
+
+	for _, o := range orders {
+		report.ByStatus[o.PaymentStatus]++
+		if o.PaymentStatus != StatusApproved {
+			continue
+		}
+		amount := AmountOf(o.Amount)
+
+		ct, ok := byCurrency[o.CurrencyType]
+		if !ok {
+			ct = &CurrencyTotals{Currency: o.CurrencyType}
+			byCurrency[o.CurrencyType] = ct
+		}
+		ct.Orders++
+		ct.Amount = ct.Amount.Add(amount)
+
+		settled, currency := o.Settlement()
+		key := pair{o.CurrencyType, currency}
+		st, ok := settlements[key]
+		if !ok {
+			st = &SettlementTotals{OrderCurrency: key.from, SettlementCurrency: key.to}
+			settlements[key] = st
+		}
+		st.Orders++
+		st.OrderAmount = st.OrderAmount.Add(amount)
+		st.SettledAmount = st.SettledAmount.Add(AmountOf(settled))
+
+		tk := terminal{o.TerminalID, o.CurrencyType}
+		tt, ok := byTerminal[tk]
+		if !ok {
+			tt = &TerminalTotals{TerminalID: tk.id, Currency: tk.currency}
+			byTerminal[tk] = tt
+		}
+		tt.Orders++
+		tt.Amount = tt.Amount.Add(amount)
+	}
+
+	for _, ct := range byCurrency {
+		report.ByCurrency = append(report.ByCurrency, *ct)
+	}
+	sort.Slice(report.ByCurrency, func(i, j int) bool {
+		return report.ByCurrency[i].Currency < report.ByCurrency[j].Currency
+	})
+
+	for _, st := range settlements {
+		report.Settlements = append(report.Settlements, *st)
+	}
+	sort.Slice(report.Settlements, func(i, j int) bool {
+		a, b := report.Settlements[i], report.Settlements[j]
+		if a.OrderCurrency != b.OrderCurrency {
+			return a.OrderCurrency < b.OrderCurrency
+		}
+		return a.SettlementCurrency < b.SettlementCurrency
+	})
+
+	for _, tt := range byTerminal {
+		report.ByTerminal = append(report.ByTerminal, *tt)
+	}
+	sort.Slice(report.ByTerminal, func(i, j int) bool {
+		a, b := report.ByTerminal[i], report.ByTerminal[j]
+		if a.TerminalID != b.TerminalID {
+			return a.TerminalID < b.TerminalID
+		}
+		return a.Currency < b.Currency
+	})
+
+	return report
+}
+
+// String formats the report as plain text
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Orders: %d\n", r.Orders)
+
+	statuses := make([]Status, 0, len(r.ByStatus))
+	for status := range r.ByStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+	for _, status := range statuses {
+		fmt.Fprintf(&b, "  %s: %d\n", status, r.ByStatus[status])
+	}
+
+	if len(r.ByCurrency) > 0 {
+		b.WriteString("Approved:\n")
+	}
+	for _, ct := range r.ByCurrency {
+		fmt.Fprintf(&b, "  %s: %d orders, %s\n", ct.Currency, ct.Orders, ct.Amount)
+	}
+	for _, st := range r.Settlements {
+		if st.OrderCurrency != st.SettlementCurrency {
+			fmt.Fprintf(&b, "  settled %s -> %s: %s -> %s\n", st.OrderCurrency, st.SettlementCurrency, st.OrderAmount, st.SettledAmount)
+		}
+	}
+	return b.String()
+}
+
+// WriteCSV writes orders as CSV with a header row
+func WriteCSV(w io.Writer, orders []OrderInfo) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"orderId", "createdDate", "status", "operation", "amount", "currency", "terminalId", "description"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, o := range orders {
+		row := []string{
+			string(o.OrderID),
+			o.CreatedDate,
+			string(o.PaymentStatus),
+			string(o.OperationType),
+			strconv.FormatFloat(o.Amount, 'f', 2, 64),
+			string(o.CurrencyType),
+			o.TerminalID,
+			o.Description,
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write order %s: %w", o.OrderID, err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package payriff
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestOptions are per-call overrides of the SDK defaults
+type RequestOptions struct {
+	Language    Language
+	Currency    Currency
+	CallbackURL string
+	// Timeout bounds the call, including retries
+	Timeout time.Duration
+	// Deadline bounds the call, including retries, by an absolute time
+	Deadline time.Time
+	// Header is sent with every attempt. Headers the SDK sets itself, such
+	// as Authorization and Content-Type, cannot be overridden
+	Header http.Header
+}
+
+// RequestOption overrides an SDK default for a single call
+type RequestOption func(*RequestOptions)
+
+// NewRequestOptions applies opts in order
+func NewRequestOptions(opts ...RequestOption) RequestOptions {
+	var o RequestOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithLanguage sets the payment page language, overriding both
+// Config.DefaultLanguage and the request's Language
+func WithLanguage(language Language) RequestOption {
+	return func(o *RequestOptions) { o.Language = language }
+}
+
+// WithCurrency sets the currency, overriding both Config.DefaultCurrency
+// and the request's Currency
+func WithCurrency(currency Currency) RequestOption {
+	return func(o *RequestOptions) { o.Currency = currency }
+}
+
+// WithCallbackURL sets the callback URL, overriding both
+// Config.DefaultCallbackURL and the request's CallbackURL
+func WithCallbackURL(url string) RequestOption {
+	return func(o *RequestOptions) { o.CallbackURL = url }
+}
+
+// WithTimeout bounds the call, including retries
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(o *RequestOptions) { o.Timeout = timeout }
+}
+
+// WithHeader adds a header to the call, e.g. for a tracing or routing proxy
+func WithHeader(key, value string) RequestOption {
+	return func(o *RequestOptions) {
+		if o.Header == nil {
+			o.Header = make(http.Header)
+		}
+		o.Header.Add(key, value)
+	}
+}
+
+type requestHeaderCtx struct{}
+
+// withOptions resolves opts and applies the timeout and headers to ctx.
+// The returned cancel func must be called
+func withOptions(ctx context.Context, opts []RequestOption) (context.Context, RequestOptions, context.CancelFunc) {
+	o := NewRequestOptions(opts...)
+	cancel := context.CancelFunc(func() {})
+	if o.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+	}
+	if !o.Deadline.IsZero() {
+		parent := cancel
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadline(ctx, o.Deadline)
+		cancel = func() {
+			cancelDeadline()
+			parent()
+		}
+	}
+	if len(o.Header) > 0 {
+		header := requestHeader(ctx).Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		for k, v := range o.Header {
+			header[k] = append(header[k], v...)
+		}
+		ctx = context.WithValue(ctx, requestHeaderCtx{}, header)
+	}
+	return ctx, o, cancel
+}
+
+// requestHeader returns the headers set with WithHeader
+func requestHeader(ctx context.Context) http.Header {
+	header, _ := ctx.Value(requestHeaderCtx{}).(http.Header)
+	return header
+}
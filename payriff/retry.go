@@ -0,0 +1,210 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+)
+
+// Jitter selects how retry delays are randomized
+type Jitter string
+
+const (
+	// JitterFull sleeps a random duration between zero and the backoff
+	JitterFull Jitter = "full"
+	// JitterEqual sleeps half the backoff plus a random duration up to the other half
+	JitterEqual Jitter = "equal"
+	// JitterDecorrelated sleeps a random duration between BaseDelay and three
+	// times the previous delay
+	JitterDecorrelated Jitter = "decorrelated"
+	// JitterNone sleeps exactly the exponential backoff
+	JitterNone Jitter = "none"
+)
+
+// RetryPolicy configures automatic retries of transient failures such as
+// network errors and gateway 502/503/504 responses. Reads are retried, and
+// mutating calls only when they carry an idempotency key, as given by the
+// RetryClass of each endpoint
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, defaults to 3
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, defaults to 200ms
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, defaults to 5s
+	MaxDelay time.Duration
+	// Jitter randomizes delays so many instances don't retry in sync,
+	// defaults to JitterFull
+	Jitter Jitter
+	// MaxElapsedTime stops retrying once this much time has passed since
+	// the first attempt, zero means no limit
+	MaxElapsedTime time.Duration
+	// Retryable decides whether a failed attempt is retried, replacing the
+	// default check for network errors and gateway statuses
+	Retryable func(err error) bool
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil {
+		return 1
+	}
+	if p.MaxAttempts > 0 {
+		return p.MaxAttempts
+	}
+	return 3
+}
+
+// backoff returns the delay before the given retry, doubling from
+// BaseDelay and randomized by the jitter strategy; prev is the previous delay
+func (p *RetryPolicy) backoff(retry int, prev time.Duration) time.Duration {
+	base, maxDelay := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = 200 * time.Millisecond
+	}
+	if maxDelay <= 0 {
+		maxDelay = 5 * time.Second
+	}
+
+	delay := base
+	for i := 1; i < retry && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+
+	switch p.Jitter {
+	case JitterNone:
+		return delay
+	case JitterEqual:
+		return delay/2 + randDuration(delay/2)
+	case JitterDecorrelated:
+		upper := max(prev*3, base)
+		return min(base+randDuration(upper-base), maxDelay)
+	default:
+		return randDuration(delay)
+	}
+}
+
+// randDuration returns a random duration in [0, d]
+func randDuration(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(d) + 1))
+}
+
+// withRetries runs attempt until it succeeds, fails permanently or the
+// policy is exhausted. An attempt is only started when the remaining
+// context time covers the backoff plus the duration of the previous
+// attempt, and the last real failure is returned instead of the context
+// error once the budget runs out
+func (s *SDK) withRetries(ctx context.Context, method, endpoint string, attempt func() (*Response, error)) (*Response, error) {
+	maxAttempts := 1
+	switch retryClass(method, endpoint) {
+	case RetrySafe:
+		maxAttempts = s.retry.maxAttempts()
+	case RetryWithKey:
+		if idempotencyKey(ctx) != "" {
+			maxAttempts = s.retry.maxAttempts()
+		}
+	}
+
+	var lastErr error
+	var delay time.Duration
+	began := time.Now()
+	for n := 1; ; n++ {
+		start := time.Now()
+		resp, err := attempt()
+		if err == nil {
+			return resp, nil
+		}
+		if lastErr != nil && ctx.Err() != nil {
+			return nil, fmt.Errorf("%w (retry stopped: %w)", lastErr, ctx.Err())
+		}
+		lastErr = err
+
+		if n >= maxAttempts || !s.retryable(ctx, err) {
+			return nil, err
+		}
+
+		delay = s.retry.backoff(n, delay)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay+time.Since(start) {
+			return nil, fmt.Errorf("%w (retry stopped: %w)", err, context.DeadlineExceeded)
+		}
+		if s.retry.MaxElapsedTime > 0 && time.Since(began)+delay > s.retry.MaxElapsedTime {
+			return nil, err
+		}
+
+		if s.hooks.OnRetry != nil {
+			event := RetryEvent{
+				Method:   method,
+				Endpoint: endpoint,
+				Attempt:  n + 1,
+				Delay:    delay,
+				Cause:    retryCause(err),
+				Err:      err,
+			}
+			s.runHook(func() { s.hooks.OnRetry(event) })
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w (retry stopped: %w)", err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable applies RetryPolicy.Retryable, falling back to isTransient.
+// Nothing is retried once the caller's context is done; an attempt that
+// timed out on its own, e.g. by Config.Timeout, is retried like any
+// other timeout
+func (s *SDK) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if s.retry == nil || s.retry.Retryable == nil {
+		return isTransient(err)
+	}
+
+	var retry bool
+	if perr := safeCall(func() error {
+		retry = s.retry.Retryable(err)
+		return nil
+	}); perr != nil {
+		s.reportError(perr)
+		return false
+	}
+	return retry
+}
+
+// isTransient reports whether err is worth retrying: timeouts, refused or
+// reset connections and temporary gateway statuses. Other network errors,
+// such as TLS or certificate failures, would fail the same way again.
+// Callers check their own context, since a per-attempt timeout also
+// matches context.DeadlineExceeded
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// Maintenance outlasts any backoff, so callers get the notice at once
+	if errors.Is(err, ErrGatewayMaintenance) {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Temporary()
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
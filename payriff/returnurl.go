@@ -0,0 +1,130 @@
+package payriff
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidReturnURL is returned for return URLs with a missing or forged signature
+	ErrInvalidReturnURL = errors.New("payriff: invalid return URL")
+	// ErrReturnURLExpired is returned for return URLs past their expiry
+	ErrReturnURLExpired = errors.New("payriff: return URL expired")
+	// ErrReturnURLUsed is returned when a return URL is presented a second time
+	ErrReturnURLUsed = errors.New("payriff: return URL already used")
+)
+
+// Query parameters added to signed return URLs
+const (
+	returnParamRef     = "ref"
+	returnParamExpires = "exp"
+	returnParamNonce   = "nonce"
+	returnParamSig     = "sig"
+)
+
+// ReturnURLSigner issues signed, single-use return URLs bound to a shopper
+// session and verifies them when the shopper comes back. The session ID is
+// part of the signature but not of the URL, so a link only works in the
+// session it was issued for
+type ReturnURLSigner struct {
+	Key []byte
+	// TTL is how long a return URL stays valid, defaults to one hour
+	TTL time.Duration
+	// Nonces records used URLs, defaults to an in-memory store
+	Nonces NonceStore
+
+	once sync.Once
+}
+
+// Sign returns base with a signed reference (such as your order or cart
+// ID) bound to sessionID
+func (s *ReturnURLSigner) Sign(base, sessionID, ref string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse return URL: %w", err)
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate return URL nonce: %w", err)
+	}
+
+	exp := strconv.FormatInt(time.Now().Add(s.ttl()).Unix(), 10)
+	n := hex.EncodeToString(nonce)
+
+	q := u.Query()
+	q.Set(returnParamRef, ref)
+	q.Set(returnParamExpires, exp)
+	q.Set(returnParamNonce, n)
+	q.Set(returnParamSig, s.sign(sessionID, ref, exp, n))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Verify checks a return URL for sessionID and returns its reference. Each
+// URL verifies only once
+func (s *ReturnURLSigner) Verify(ctx context.Context, u *url.URL, sessionID string) (string, error) {
+	s.once.Do(func() {
+		if s.Nonces == nil {
+			s.Nonces = &MemoryNonceStore{}
+		}
+	})
+
+	q := u.Query()
+	ref, exp, nonce, sig := q.Get(returnParamRef), q.Get(returnParamExpires), q.Get(returnParamNonce), q.Get(returnParamSig)
+	if sig == "" || nonce == "" || exp == "" {
+		return "", ErrInvalidReturnURL
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.sign(sessionID, ref, exp, nonce))) {
+		return "", ErrInvalidReturnURL
+	}
+
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidReturnURL, err)
+	}
+	expires := time.Unix(unix, 0)
+	if time.Now().After(expires) {
+		return "", ErrReturnURLExpired
+	}
+
+	fresh, err := s.Nonces.Use(ctx, nonce, expires)
+	if err != nil {
+		return "", fmt.Errorf("failed to record return URL nonce: %w", err)
+	}
+	if !fresh {
+		return "", ErrReturnURLUsed
+	}
+	return ref, nil
+}
+
+// VerifyRequest verifies the URL of a returning shopper's request
+func (s *ReturnURLSigner) VerifyRequest(r *http.Request, sessionID string) (string, error) {
+	return s.Verify(r.Context(), r.URL, sessionID)
+}
+
+func (s *ReturnURLSigner) sign(sessionID, ref, exp, nonce string) string {
+	mac := hmac.New(sha256.New, s.Key)
+	for _, part := range []string{sessionID, ref, exp, nonce} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *ReturnURLSigner) ttl() time.Duration {
+	if s.TTL > 0 {
+		return s.TTL
+	}
+	return time.Hour
+}
@@ -0,0 +1,38 @@
+package payriff
+
+import (
+	"context"
+	"net/http"
+)
+
+// ReverseRequest releases a pre-authorized amount. Amount defaults to the
+// full pre-authorized amount when nil
+type ReverseRequest struct {
+	OrderID OrderID `json:"orderId"`
+	Amount  *Amount `json:"amount,omitempty"`
+}
+
+// ReversePayload is the result of a reversal
+type ReversePayload struct {
+	OrderID       OrderID `json:"orderId"`
+	Amount        float64 `json:"amount"`
+	PaymentStatus Status  `json:"paymentStatus"`
+}
+
+// Reverse voids a PRE_AUTH order that will not be completed, releasing the
+// held funds on the shopper's card
+func (s *SDK) Reverse(ctx context.Context, req ReverseRequest, opts ...RequestOption) (*ApiResponse[ReversePayload], error) {
+	ctx, _, cancel := withOptions(ctx, opts)
+	defer cancel()
+
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.makeRequest(ctx, "/reverse", http.MethodPost, ScopeSecret, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse[ReversePayload](s, "POST /reverse", resp)
+}
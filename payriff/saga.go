@@ -0,0 +1,177 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// StepState is the outcome of a saga step recorded in the journal
+type StepState string
+
+const (
+	StepCompleted          StepState = "completed"
+	StepFailed             StepState = "failed"
+	StepCompensated        StepState = "compensated"
+	StepCompensationFailed StepState = "compensation_failed"
+)
+
+// JournalEntry records the progress of one step of a multi-step flow
+type JournalEntry struct {
+	FlowID string
+	Step   string
+	State  StepState
+	Error  string
+	// Operator is the acting user set with WithOperator
+	Operator string
+	Time     time.Time
+}
+
+// Journal durably records flow progress so an interrupted flow can resume
+// or compensate after a restart
+type Journal interface {
+	Append(ctx context.Context, entry JournalEntry) error
+	Entries(ctx context.Context, flowID string) ([]JournalEntry, error)
+}
+
+// SagaStep is a stage of a saga with an optional compensating action
+type SagaStep struct {
+	Name string
+	Do   func(ctx context.Context) error
+	// Compensate undoes Do after a later step fails, e.g. reversing a pre-auth
+	Compensate func(ctx context.Context) error
+}
+
+// SagaError reports a failed saga step and any compensation failures
+type SagaError struct {
+	FlowID string
+	Step   string
+	Err    error
+	// CompensationErrs holds failures of compensating actions, which leave
+	// the flow needing manual attention
+	CompensationErrs []error
+}
+
+func (e *SagaError) Error() string {
+	msg := fmt.Sprintf("payriff: saga %s failed at step %s: %v", e.FlowID, e.Step, e.Err)
+	if len(e.CompensationErrs) > 0 {
+		msg += fmt.Sprintf(" (%d compensations failed)", len(e.CompensationErrs))
+	}
+	return msg
+}
+
+func (e *SagaError) Unwrap() []error {
+	return append([]error{e.Err}, e.CompensationErrs...)
+}
+
+// Saga runs steps in order and, when one fails, runs the compensations of
+// the completed steps in reverse. Progress is journaled, so running the
+// same flow ID again skips steps that already completed
+type Saga struct {
+	ID      string
+	Journal Journal
+	Steps   []SagaStep
+}
+
+// Run executes the saga
+func (s *Saga) Run(ctx context.Context) error {
+	entries, err := s.Journal.Entries(ctx, s.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load saga journal: %w", err)
+	}
+
+	done := make(map[string]StepState)
+	for _, e := range entries {
+		done[e.Step] = e.State
+	}
+
+	var completed []SagaStep
+	for _, step := range s.Steps {
+		if done[step.Name] == StepCompleted {
+			completed = append(completed, step)
+			continue
+		}
+
+		err := safeCall(func() error { return step.Do(ctx) })
+		if err == nil {
+			if err := s.record(ctx, step.Name, StepCompleted, nil); err != nil {
+				return err
+			}
+			completed = append(completed, step)
+			continue
+		}
+
+		sagaErr := &SagaError{FlowID: s.ID, Step: step.Name, Err: err}
+		if jerr := s.record(ctx, step.Name, StepFailed, err); jerr != nil {
+			sagaErr.CompensationErrs = append(sagaErr.CompensationErrs, jerr)
+		}
+		sagaErr.CompensationErrs = append(sagaErr.CompensationErrs, s.compensate(ctx, completed)...)
+		return sagaErr
+	}
+	return nil
+}
+
+func (s *Saga) compensate(ctx context.Context, completed []SagaStep) []error {
+	var errs []error
+	for i := len(completed) - 1; i >= 0; i-- {
+		step := completed[i]
+		if step.Compensate == nil {
+			continue
+		}
+
+		if err := safeCall(func() error { return step.Compensate(ctx) }); err != nil {
+			errs = append(errs, fmt.Errorf("failed to compensate step %s: %w", step.Name, err))
+			if jerr := s.record(ctx, step.Name, StepCompensationFailed, err); jerr != nil {
+				errs = append(errs, jerr)
+			}
+			continue
+		}
+		if err := s.record(ctx, step.Name, StepCompensated, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (s *Saga) record(ctx context.Context, step string, state StepState, cause error) error {
+	entry := JournalEntry{FlowID: s.ID, Step: step, State: state, Operator: OperatorFrom(ctx), Time: time.Now()}
+	if cause != nil {
+		entry.Error = cause.Error()
+	}
+	if err := s.Journal.Append(ctx, entry); err != nil {
+		return fmt.Errorf("failed to journal step %s: %w", step, err)
+	}
+	return nil
+}
+
+// MemoryJournal is an in-process Journal
+type MemoryJournal struct {
+	mu      sync.Mutex
+	entries map[string][]JournalEntry
+}
+
+// Append implements Journal
+func (j *MemoryJournal) Append(ctx context.Context, entry JournalEntry) error {
+	if entry.FlowID == "" {
+		return errors.New("payriff: journal entry has no flow ID")
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.entries == nil {
+		j.entries = make(map[string][]JournalEntry)
+	}
+	j.entries[entry.FlowID] = append(j.entries[entry.FlowID], entry)
+	return nil
+}
+
+// Entries implements Journal
+func (j *MemoryJournal) Entries(ctx context.Context, flowID string) ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return append([]JournalEntry(nil), j.entries[flowID]...), nil
+}
@@ -0,0 +1,88 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrCardNotSaved is returned by CaptureSavedCard for orders that saved no card
+var ErrCardNotSaved = errors.New("payriff: order has no saved card")
+
+// SaveCardRequest starts a card tokenization flow
+type SaveCardRequest struct {
+	// Amount defaults to Config.CardSaveAmount
+	Amount      Amount
+	Description string
+	Language    Language
+	Currency    Currency
+	CallbackURL string
+	Theme       *PageTheme
+}
+
+// SaveCard creates a pre-authorization order with card saving enabled. Send
+// the shopper to the returned payment URL; once the order is approved,
+// CaptureSavedCard returns the card for AutoPay and releases the hold
+func (s *SDK) SaveCard(ctx context.Context, req SaveCardRequest) (*ApiResponse[OrderPayload], error) {
+	if !req.Amount.IsPositive() {
+		req.Amount = s.cardSaveAmount
+	}
+	if req.Description == "" {
+		req.Description = "Card verification"
+	}
+
+	return s.CreateOrderContext(ctx, CreateOrderRequest{
+		Amount:      req.Amount,
+		Description: req.Description,
+		CardSave:    true,
+		Operation:   OperationPreAuth,
+		Language:    req.Language,
+		Currency:    req.Currency,
+		CallbackURL: req.CallbackURL,
+		Theme:       req.Theme,
+	})
+}
+
+// CaptureSavedCard returns the card saved by an approved SaveCard order and
+// reverses its verification pre-authorization, so the held amount is
+// released at once. Orders already reversed return the card as well. When
+// the reversal fails, the card is returned with the error
+func (s *SDK) CaptureSavedCard(ctx context.Context, orderID OrderID) (CardUUID, error) {
+	info, err := s.GetOrderInfoContext(ctx, orderID)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsSuccessful() {
+		return "", info.Err()
+	}
+	cardUUID, ok := info.Payload.SavedCardUUID()
+	if !ok {
+		return "", fmt.Errorf("%w: order %s is %s", ErrCardNotSaved, orderID, info.Payload.PaymentStatus)
+	}
+	if info.Payload.PaymentStatus != StatusPreAuthApproved {
+		return cardUUID, nil
+	}
+
+	resp, err := s.Reverse(ctx, ReverseRequest{OrderID: orderID})
+	if err != nil {
+		return cardUUID, fmt.Errorf("failed to reverse card verification %s: %w", orderID, err)
+	}
+	if !resp.IsSuccessful() {
+		return cardUUID, resp.Err()
+	}
+	return cardUUID, nil
+}
+
+// SavedCardUUID returns the UUID of the card saved by an approved order,
+// or by a card verification that was reversed after the card was saved
+func (o OrderInfo) SavedCardUUID() (CardUUID, bool) {
+	if !o.PaymentStatus.IsSuccessful() && o.PaymentStatus != StatusReverse {
+		return "", false
+	}
+	for i := len(o.Transactions) - 1; i >= 0; i-- {
+		if uuid := o.Transactions[i].CardUUID; uuid != nil && *uuid != "" {
+			return *uuid, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,165 @@
+package payriff
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ReportPeriod is how often a ReportSchedule fires
+type ReportPeriod string
+
+const (
+	ReportDaily  ReportPeriod = "daily"
+	ReportWeekly ReportPeriod = "weekly"
+)
+
+// ReportSchedule fires once a day, or once a week on Weekday, at Hour:Minute
+type ReportSchedule struct {
+	Period  ReportPeriod
+	Weekday time.Weekday
+	Hour    int
+	Minute  int
+	// Location defaults to time.UTC
+	Location *time.Location
+}
+
+// Next returns the first firing time strictly after t
+func (s ReportSchedule) Next(t time.Time) time.Time {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+
+	next := time.Date(t.Year(), t.Month(), t.Day(), s.Hour, s.Minute, 0, 0, loc)
+	if s.Period == ReportWeekly {
+		next = next.AddDate(0, 0, (int(s.Weekday)-int(next.Weekday())+7)%7)
+	}
+	for !next.After(t) {
+		next = next.AddDate(0, 0, s.days())
+	}
+	return next
+}
+
+// days returns the length of the period covered by one report
+func (s ReportSchedule) days() int {
+	if s.Period == ReportWeekly {
+		return 7
+	}
+	return 1
+}
+
+// ReportSource returns the orders created in [from, to)
+type ReportSource interface {
+	Orders(ctx context.Context, from, to time.Time) ([]OrderInfo, error)
+}
+
+// ReportSourceFunc adapts a function to the ReportSource interface
+type ReportSourceFunc func(ctx context.Context, from, to time.Time) ([]OrderInfo, error)
+
+// Orders calls f(ctx, from, to)
+func (f ReportSourceFunc) Orders(ctx context.Context, from, to time.Time) ([]OrderInfo, error) {
+	return f(ctx, from, to)
+}
+
+// ReportScheduler delivers the Summarize report of each period through a
+// Notifier, with a CSV export of the orders attached when CSV is set
+type ReportScheduler struct {
+	Schedule ReportSchedule
+	Source   ReportSource
+	Notifier Notifier
+	// CSV attaches the orders of the period as orders.csv
+	CSV bool
+	// Name prefixes the subject, defaults to "Payriff"
+	Name string
+	// Commissions, when set, lists orders of the period charged an
+	// off-contract commission rate in the report
+	Commissions *CommissionAuditor
+}
+
+// Deliver builds and sends the report for orders created in [from, to)
+func (r *ReportScheduler) Deliver(ctx context.Context, from, to time.Time) error {
+	if r.Source == nil || r.Notifier == nil {
+		return errors.New("payriff: report scheduler needs a source and a notifier")
+	}
+
+	var orders []OrderInfo
+	if err := safeCall(func() (err error) {
+		orders, err = r.Source.Orders(ctx, from, to)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to load orders: %w", err)
+	}
+
+	report := Summarize(orders)
+	name := r.Name
+	if name == "" {
+		name = "Payriff"
+	}
+	n := Notification{
+		Kind:    NotificationReport,
+		Subject: fmt.Sprintf("%s %s report %s", name, r.Schedule.periodName(), from.Format(time.DateOnly)),
+		Body:    fmt.Sprintf("Period: %s - %s\n%s", from.Format(time.DateTime), to.Format(time.DateTime), report),
+		Data: map[string]any{
+			"from":   from,
+			"to":     to,
+			"report": report,
+		},
+	}
+	if r.Commissions != nil {
+		if found := r.Commissions.Discrepancies(orders); len(found) > 0 {
+			n.Data["commissionDisputes"] = found
+			n.Body += fmt.Sprintf("\nCommission discrepancies: %d", len(found))
+			for _, d := range found {
+				n.Body += "\n  " + d.String()
+			}
+		}
+	}
+	if r.CSV {
+		var buf bytes.Buffer
+		if err := WriteCSV(&buf, orders); err != nil {
+			return err
+		}
+		n.Attachments = append(n.Attachments, Attachment{
+			Name:        fmt.Sprintf("orders-%s.csv", from.Format(time.DateOnly)),
+			ContentType: "text/csv",
+			Content:     buf.Bytes(),
+		})
+	}
+
+	if err := safeCall(func() error { return r.Notifier.Notify(ctx, n) }); err != nil {
+		return fmt.Errorf("failed to deliver report: %w", err)
+	}
+	return nil
+}
+
+// Run delivers a report at every firing time until ctx is done. Each report
+// covers the period that ended at the firing time; failures are passed to
+// onError, which may be nil
+func (r *ReportScheduler) Run(ctx context.Context, onError func(error)) error {
+	for {
+		next := r.Schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		from := next.AddDate(0, 0, -r.Schedule.days())
+		if err := r.Deliver(ctx, from, next); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+func (s ReportSchedule) periodName() string {
+	if s.Period == ReportWeekly {
+		return "weekly"
+	}
+	return "daily"
+}
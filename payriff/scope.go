@@ -0,0 +1,68 @@
+package payriff
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrSecretKeyRequired is returned for operations that need the secret key
+// when the SDK was configured with a public key only
+var ErrSecretKeyRequired = errors.New("payriff: operation requires the secret key")
+
+// HeaderKeyID identifies which of several configured keys signed a request
+const HeaderKeyID = "X-Payriff-Key-Id"
+
+// KeyScope is the credential an operation requires
+type KeyScope string
+
+const (
+	// ScopePublic operations only read data and accept the public key
+	ScopePublic KeyScope = "public"
+	// ScopeSecret operations move money or change state and need the secret key
+	ScopeSecret KeyScope = "secret"
+)
+
+// APIKey is a secret key registered with the gateway under an ID
+type APIKey struct {
+	ID     string
+	Secret string
+	// ActiveFrom is when the SDK starts using the key. The zero value means
+	// the key is active immediately
+	ActiveFrom time.Time
+}
+
+// activeKey returns the most recently activated key in keys
+func activeKey(keys []APIKey, now time.Time) (APIKey, bool) {
+	var (
+		active APIKey
+		found  bool
+	)
+	for _, k := range keys {
+		if k.ActiveFrom.After(now) {
+			continue
+		}
+		if !found || k.ActiveFrom.After(active.ActiveFrom) {
+			active, found = k, true
+		}
+	}
+	return active, found
+}
+
+// keyFor returns the credential to send for an operation of the given scope
+func (s *SDK) keyFor(scope KeyScope) (APIKey, error) {
+	if scope == ScopePublic && s.publicKey != "" {
+		return APIKey{Secret: s.publicKey}, nil
+	}
+	if k, ok := activeKey(s.keys, time.Now()); ok {
+		return k, nil
+	}
+	if len(s.keys) > 0 && s.secretKey == "" && s.auth == nil {
+		// Every key is future-dated, and sending an empty secret would only
+		// surface as an opaque authorization failure
+		return APIKey{}, &ConfigError{Field: "Keys", Problem: "has no key active yet and SecretKey is empty"}
+	}
+	if scope == ScopeSecret && s.secretKey == "" && s.publicKey != "" {
+		return APIKey{}, ErrSecretKeyRequired
+	}
+	return APIKey{Secret: s.secretKey}, nil
+}
@@ -0,0 +1,120 @@
+package payriff
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// CallbackServer serves a callback handler over HTTPS, optionally
+// requiring client certificates for mutual TLS
+type CallbackServer struct {
+	Addr    string
+	Handler http.Handler
+
+	// CertFile and KeyFile hold the server certificate and key
+	CertFile string
+	KeyFile  string
+	// GetCertificate overrides CertFile/KeyFile, e.g. autocert.Manager.GetCertificate
+	GetCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
+
+	// ClientCAFile or ClientCAs enable mutual TLS: clients must present a
+	// certificate signed by one of these authorities
+	ClientCAFile string
+	ClientCAs    *x509.CertPool
+
+	mu       sync.Mutex
+	server   *http.Server
+	shutdown bool
+}
+
+// TLSConfig builds the TLS configuration used by the server
+func (s *CallbackServer) TLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: s.GetCertificate,
+	}
+
+	if s.GetCertificate == nil {
+		if s.CertFile == "" || s.KeyFile == "" {
+			return nil, errors.New("payriff: callback server needs CertFile and KeyFile or GetCertificate")
+		}
+		cert, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load server certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	pool := s.ClientCAs
+	if s.ClientCAFile != "" {
+		pem, err := os.ReadFile(s.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		// The caller's pool is left as is
+		if pool == nil {
+			pool = x509.NewCertPool()
+		} else {
+			pool = pool.Clone()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("payriff: no certificates found in %s", s.ClientCAFile)
+		}
+	}
+	if pool != nil {
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
+}
+
+// ListenAndServeTLS starts the server and blocks until it stops. After
+// Shutdown it returns http.ErrServerClosed without listening
+func (s *CallbackServer) ListenAndServeTLS() error {
+	cfg, err := s.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.shutdown {
+		s.mu.Unlock()
+		return http.ErrServerClosed
+	}
+	server := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s.Handler,
+		TLSConfig:         cfg,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	s.server = server
+	s.mu.Unlock()
+
+	err = server.ListenAndServeTLS("", "")
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown gracefully stops the server. Called before ListenAndServeTLS,
+// it keeps the server from starting
+func (s *CallbackServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shutdown = true
+	server := s.server
+	s.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
@@ -0,0 +1,197 @@
+package payriff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// ShadowConfig mirrors a share of read requests to an alternate gateway
+// base URL or API version and reports differences through Hooks.OnShadowDiff.
+// Write requests are never mirrored
+type ShadowConfig struct {
+	// BaseURL defaults to the primary base URL
+	BaseURL string
+	// APIVersion defaults to the version in BaseURL or the primary version
+	APIVersion string
+	// Rate is the fraction of read requests to mirror, from 0 to 1
+	Rate float64
+	// Ignore lists field paths left out of the comparison, defaults to
+	// responseId and route
+	Ignore []string
+}
+
+// FieldDiff is a difference between two JSON documents. Path uses dots for
+// object keys and brackets for array indices, e.g. payload.transactions[0].amount
+type FieldDiff struct {
+	Path string
+	// A and B are the decoded values, nil when the field is missing
+	A any
+	B any
+}
+
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: %v != %v", d.Path, d.A, d.B)
+}
+
+// ShadowDiff reports the comparison of a mirrored read request
+type ShadowDiff struct {
+	Method   string
+	Endpoint string
+	Diffs    []FieldDiff
+	// Err is set when the shadow request failed
+	Err error
+}
+
+// shadowRoute is the mirrored destination of read requests
+type shadowRoute struct {
+	sdk    *SDK
+	rate   float64
+	ignore map[string]bool
+}
+
+func newShadowRoute(s *SDK, cfg *ShadowConfig) *shadowRoute {
+	shadow := *s
+	shadow.health = newHealth(s.health.threshold, s.health.recovery)
+	shadow.shadow = nil
+	// Mirrored calls are not real traffic: they are left out of metrics
+	// and hooks, and logged with a shadow attribute
+	shadow.metrics = nil
+	shadow.hooks = Hooks{}
+	if s.logger != nil {
+		shadow.logger = s.logger.With(slog.Bool("shadow", true))
+	}
+	if cfg.BaseURL != "" {
+		shadow.baseURL, shadow.apiVersion = splitBaseURL(cfg.BaseURL)
+	}
+	if cfg.APIVersion != "" {
+		shadow.apiVersion = cfg.APIVersion
+	}
+
+	ignore := cfg.Ignore
+	if ignore == nil {
+		ignore = []string{"responseId", "route"}
+	}
+	route := &shadowRoute{sdk: &shadow, rate: cfg.Rate, ignore: make(map[string]bool)}
+	for _, path := range ignore {
+		route.ignore[path] = true
+	}
+	return route
+}
+
+// mirror replays a successful read request against the shadow route in
+// the background and reports the differences
+func (s *SDK) mirror(ctx context.Context, endpoint, method string, key APIKey, primary *Response) {
+	route := s.shadow
+	if route == nil || method != http.MethodGet || rand.Float64() >= route.rate {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		report := ShadowDiff{Method: method, Endpoint: endpoint}
+		shadow, err := route.sdk.doRequest(ctx, endpoint, method, key, nil)
+		if err != nil {
+			report.Err = err
+		} else {
+			report.Diffs, report.Err = diffResponses(primary, shadow, route.ignore)
+		}
+
+		if s.hooks.OnShadowDiff != nil {
+			s.runHook(func() { s.hooks.OnShadowDiff(report) })
+		}
+	}()
+}
+
+// diffResponses compares two responses field by field
+func diffResponses(a, b any, ignore map[string]bool) ([]FieldDiff, error) {
+	av, err := toJSONValue(a)
+	if err != nil {
+		return nil, err
+	}
+	bv, err := toJSONValue(b)
+	if err != nil {
+		return nil, err
+	}
+	return diffJSON("", av, bv, ignore), nil
+}
+
+// toJSONValue round-trips v through JSON into maps, slices and scalars
+func toJSONValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value for comparison: %w", err)
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode value for comparison: %w", err)
+	}
+	return out, nil
+}
+
+// diffJSON returns the differences between two decoded JSON values
+func diffJSON(path string, a, b any, ignore map[string]bool) []FieldDiff {
+	if ignore[path] {
+		return nil
+	}
+
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := make(map[string]bool, len(av)+len(bv))
+		for k := range av {
+			keys[k] = true
+		}
+		for k := range bv {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		var diffs []FieldDiff
+		for _, k := range sorted {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			diffs = append(diffs, diffJSON(child, av[k], bv[k], ignore)...)
+		}
+		return diffs
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+
+		var diffs []FieldDiff
+		for i := 0; i < max(len(av), len(bv)); i++ {
+			var x, y any
+			if i < len(av) {
+				x = av[i]
+			}
+			if i < len(bv) {
+				y = bv[i]
+			}
+			diffs = append(diffs, diffJSON(path+"["+strconv.Itoa(i)+"]", x, y, ignore)...)
+		}
+		return diffs
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	return []FieldDiff{{Path: path, A: a, B: b}}
+}
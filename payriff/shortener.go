@@ -0,0 +1,123 @@
+package payriff
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// URLShortener shortens payment URLs before the SDK returns them, e.g. to
+// fit links into SMS messages
+type URLShortener interface {
+	Shorten(ctx context.Context, url string) (string, error)
+}
+
+// URLShortenerFunc adapts a function to the URLShortener interface
+type URLShortenerFunc func(ctx context.Context, url string) (string, error)
+
+// Shorten calls f(ctx, url)
+func (f URLShortenerFunc) Shorten(ctx context.Context, url string) (string, error) {
+	return f(ctx, url)
+}
+
+// NoopShortener returns URLs unchanged. It is the default URLShortener
+var NoopShortener URLShortener = URLShortenerFunc(func(ctx context.Context, url string) (string, error) {
+	return url, nil
+})
+
+// shortenURL applies the configured shortener. Shortening is cosmetic, so
+// a failure is reported through Hooks.OnError and the full URL is kept
+func (s *SDK) shortenURL(ctx context.Context, url string) string {
+	if s.shortener == nil || url == "" {
+		return url
+	}
+
+	var short string
+	err := safeCall(func() error {
+		var err error
+		short, err = s.shortener.Shorten(ctx, url)
+		return err
+	})
+	if err != nil {
+		s.reportError(fmt.Errorf("failed to shorten payment URL: %w", err))
+		return url
+	}
+	return short
+}
+
+// shortCodeAlphabet is the alphabet of RedirectShortener codes
+const shortCodeAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// RedirectShortener is an example URLShortener that serves its own short
+// links: Shorten maps a URL to BaseURL plus a random code, reusing the code
+// of a URL shortened before, and ServeHTTP redirects the code to the URL. Links are kept in memory, so use it for
+// single instance deployments or as a template for a persistent one
+type RedirectShortener struct {
+	// BaseURL is where the shortener is mounted, e.g. https://pay.example.com/l/
+	BaseURL string
+	// CodeLength defaults to 7
+	CodeLength int
+
+	mu    sync.Mutex
+	links map[string]string
+	codes map[string]string
+}
+
+// Shorten implements URLShortener
+func (r *RedirectShortener) Shorten(ctx context.Context, url string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.links == nil {
+		r.links = make(map[string]string)
+		r.codes = make(map[string]string)
+	}
+	base := strings.TrimSuffix(r.BaseURL, "/") + "/"
+	if code, ok := r.codes[url]; ok {
+		return base + code, nil
+	}
+	for {
+		code, err := r.code()
+		if err != nil {
+			return "", err
+		}
+		if _, taken := r.links[code]; taken {
+			continue
+		}
+		r.links[code] = url
+		r.codes[url] = code
+		return base + code, nil
+	}
+}
+
+// ServeHTTP redirects a short link to its payment URL
+func (r *RedirectShortener) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	code := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+
+	r.mu.Lock()
+	url, ok := r.links[code]
+	r.mu.Unlock()
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	http.Redirect(w, req, url, http.StatusFound)
+}
+
+func (r *RedirectShortener) code() (string, error) {
+	n := r.CodeLength
+	if n <= 0 {
+		n = 7
+	}
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate short link code: %w", err)
+	}
+	for i := range b {
+		b[i] = shortCodeAlphabet[int(b[i])%len(shortCodeAlphabet)]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,136 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+	"time"
+)
+
+// OrderSnapshot is the recorded state of an order
+type OrderSnapshot struct {
+	OrderID       OrderID
+	PaymentStatus Status
+	Amount        float64
+	Transactions  int
+	// Hash is the ContentHash of the canonical order JSON
+	Hash    string
+	TakenAt time.Time
+}
+
+// SnapshotStore persists the latest snapshot of each order
+type SnapshotStore interface {
+	// Load returns the snapshot of an order, ok is false when there is none
+	Load(ctx context.Context, orderID OrderID) (snap OrderSnapshot, ok bool, err error)
+	Save(ctx context.Context, snap OrderSnapshot) error
+}
+
+// OrderChange reports an order whose state differs from its last snapshot
+type OrderChange struct {
+	OrderID  OrderID
+	Previous OrderSnapshot
+	Current  OrderSnapshot
+	Order    OrderInfo
+}
+
+// StatusChanged reports whether the payment status flipped
+func (c OrderChange) StatusChanged() bool {
+	return c.Previous.PaymentStatus != c.Current.PaymentStatus
+}
+
+// Snapshotter fetches orders, compares them with the previous run and
+// reports orders that changed, catching status flips and late refunds that
+// no callback announced. Orders seen for the first time are recorded
+// without being reported
+type Snapshotter struct {
+	SDK   *SDK
+	Store SnapshotStore
+}
+
+// Run snapshots every order yielded by orderIDs and returns the changed
+// ones. Orders that fail to load are skipped and their errors joined
+func (s *Snapshotter) Run(ctx context.Context, orderIDs iter.Seq[OrderID]) ([]OrderChange, error) {
+	if s.SDK == nil || s.Store == nil {
+		return nil, errors.New("payriff: snapshotter needs an SDK and a store")
+	}
+
+	var changes []OrderChange
+	var errs []error
+	for order, err := range s.SDK.Orders(ctx, orderIDs) {
+		if err != nil {
+			if ctx.Err() != nil {
+				return changes, errors.Join(append(errs, err)...)
+			}
+			errs = append(errs, fmt.Errorf("failed to fetch order %s: %w", order.OrderID, err))
+			continue
+		}
+
+		current, err := Snapshot(order)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		previous, ok, err := s.Store.Load(ctx, order.OrderID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to load snapshot of order %s: %w", order.OrderID, err))
+			continue
+		}
+		if ok && previous.Hash == current.Hash {
+			continue
+		}
+
+		if err := s.Store.Save(ctx, current); err != nil {
+			errs = append(errs, fmt.Errorf("failed to save snapshot of order %s: %w", order.OrderID, err))
+			continue
+		}
+		if ok {
+			changes = append(changes, OrderChange{OrderID: order.OrderID, Previous: previous, Current: current, Order: order})
+		}
+	}
+	return changes, errors.Join(errs...)
+}
+
+// Snapshot builds the snapshot of an order
+func Snapshot(order OrderInfo) (OrderSnapshot, error) {
+	data, err := CanonicalJSON(order)
+	if err != nil {
+		return OrderSnapshot{}, fmt.Errorf("failed to encode order %s: %w", order.OrderID, err)
+	}
+	return OrderSnapshot{
+		OrderID:       order.OrderID,
+		PaymentStatus: order.PaymentStatus,
+		Amount:        order.Amount,
+		Transactions:  len(order.Transactions),
+		Hash:          ContentHash(data),
+		TakenAt:       time.Now(),
+	}, nil
+}
+
+// MemorySnapshotStore is an in-process SnapshotStore
+type MemorySnapshotStore struct {
+	mu    sync.Mutex
+	snaps map[OrderID]OrderSnapshot
+}
+
+// Load implements SnapshotStore
+func (m *MemorySnapshotStore) Load(ctx context.Context, orderID OrderID) (OrderSnapshot, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snap, ok := m.snaps[orderID]
+	return snap, ok, nil
+}
+
+// Save implements SnapshotStore
+func (m *MemorySnapshotStore) Save(ctx context.Context, snap OrderSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.snaps == nil {
+		m.snaps = make(map[OrderID]OrderSnapshot)
+	}
+	m.snaps[snap.OrderID] = snap
+	return nil
+}
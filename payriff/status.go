@@ -0,0 +1,198 @@
+package payriff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownValue is returned by ParseStatus, ParseOperation and
+// ParseResultCode for values the SDK does not know
+var ErrUnknownValue = errors.New("payriff: unknown value")
+
+// KnownStatuses lists the payment statuses the SDK knows
+var KnownStatuses = []Status{
+	StatusCreated,
+	StatusApproved,
+	StatusCanceled,
+	StatusDeclined,
+	StatusRefunded,
+	StatusPreAuthApproved,
+	StatusExpired,
+	StatusReverse,
+	StatusPartialRefund,
+}
+
+// KnownOperations lists the operations the SDK knows
+var KnownOperations = []Operation{OperationPurchase, OperationPreAuth}
+
+// KnownResultCodes lists the result codes the SDK knows
+var KnownResultCodes = []ResultCode{
+	ResultCodeSuccess,
+	ResultCodeSuccessGateway,
+	ResultCodeSuccessApprove,
+	ResultCodeSuccessPreauth,
+	ResultCodeWarning,
+	ResultCodeError,
+	ResultCodeInvalidParameters,
+	ResultCodeUnauthorized,
+	ResultCodeTokenNotPresent,
+	ResultCodeInvalidToken,
+}
+
+// statusAliases maps spellings seen in exports and older APIs
+var statusAliases = map[string]Status{
+	"CANCELLED":          StatusCanceled,
+	"REVERSED":           StatusReverse,
+	"PARTIALLY_REFUNDED": StatusPartialRefund,
+	"PREAUTHAPPROVED":    StatusPreAuthApproved,
+}
+
+// operationAliases maps spellings seen in exports and older APIs
+var operationAliases = map[string]Operation{
+	"PREAUTH": OperationPreAuth,
+	"SALE":    OperationPurchase,
+}
+
+// normalizeName upper-cases s and joins its words with underscores, so
+// " preauth-approved " becomes PREAUTH_APPROVED
+func normalizeName(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '\t'
+	}), "_")
+}
+
+// ParseStatus normalizes case, whitespace and separators of s and checks
+// it against KnownStatuses. For unknown statuses the normalized value is
+// returned with an error matching ErrUnknownValue, so lenient callers can
+// keep it
+func ParseStatus(s string) (Status, error) {
+	name := normalizeName(s)
+	if alias, ok := statusAliases[name]; ok {
+		return alias, nil
+	}
+	status := Status(name)
+	if !slices.Contains(KnownStatuses, status) {
+		return status, fmt.Errorf("%w: status %q", ErrUnknownValue, s)
+	}
+	return status, nil
+}
+
+// ParseOperation is ParseStatus for operations
+func ParseOperation(s string) (Operation, error) {
+	name := normalizeName(s)
+	if alias, ok := operationAliases[name]; ok {
+		return alias, nil
+	}
+	operation := Operation(name)
+	if !slices.Contains(KnownOperations, operation) {
+		return operation, fmt.Errorf("%w: operation %q", ErrUnknownValue, s)
+	}
+	return operation, nil
+}
+
+// ParseResultCode trims and upper-cases s and checks it against
+// KnownResultCodes. Separators are kept, since codes such as
+// PREAUTH-APPROVED use them. For unknown codes the normalized value is
+// returned with an error matching ErrUnknownValue
+func ParseResultCode(s string) (ResultCode, error) {
+	code := ResultCode(strings.ToUpper(strings.TrimSpace(s)))
+	if !slices.Contains(KnownResultCodes, code) {
+		return code, fmt.Errorf("%w: result code %q", ErrUnknownValue, s)
+	}
+	return code, nil
+}
+
+// UnmarshalJSON normalizes the status with ParseStatus, keeping unknown
+// statuses so new gateway values do not break decoding
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s, _ = ParseStatus(raw)
+	return nil
+}
+
+// UnmarshalJSON normalizes the operation with ParseOperation, keeping
+// unknown operations
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*o, _ = ParseOperation(raw)
+	return nil
+}
+
+// UnmarshalJSON normalizes the code with ParseResultCode, keeping unknown
+// codes. Numeric codes are accepted as written
+func (c *ResultCode) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var n json.Number
+		if json.Unmarshal(data, &n) != nil {
+			return err
+		}
+		raw = n.String()
+	}
+	*c, _ = ParseResultCode(raw)
+	return nil
+}
+
+// IsTerminal reports whether the shopper's part of the order is over.
+// PREAUTH_APPROVED counts because a pre-authorization stays there until
+// the merchant completes or reverses it
+func (s Status) IsTerminal() bool {
+	switch s {
+	case StatusApproved, StatusPreAuthApproved, StatusDeclined, StatusCanceled, StatusExpired,
+		StatusRefunded, StatusPartialRefund, StatusReverse:
+		return true
+	}
+	return false
+}
+
+// IsSuccessful reports whether the payment was authorized, either charged
+// (APPROVED) or held (PREAUTH_APPROVED)
+func (s Status) IsSuccessful() bool {
+	return s == StatusApproved || s == StatusPreAuthApproved
+}
+
+// IsFailed reports whether the order ended without a payment
+func (s Status) IsFailed() bool {
+	return s == StatusDeclined || s == StatusCanceled || s == StatusExpired
+}
+
+// IsPaid reports whether the shopper paid, including orders refunded since
+func (s Status) IsPaid() bool {
+	return s.IsSuccessful() || s.IsRefunded()
+}
+
+// IsRefunded reports whether the order was fully or partially refunded
+func (s Status) IsRefunded() bool {
+	return s == StatusRefunded || s == StatusPartialRefund
+}
+
+// IsRefundable reports whether (more of) the order can be refunded
+func (s Status) IsRefundable() bool {
+	return s == StatusApproved || s == StatusPartialRefund
+}
+
+// IsCapturable reports whether the order is a held pre-authorization that
+// can be completed or reversed
+func (s Status) IsCapturable() bool {
+	return s == StatusPreAuthApproved
+}
+
+// IsPreAuth reports whether the operation holds funds for later completion
+func (o Operation) IsPreAuth() bool {
+	return o == OperationPreAuth
+}
+
+// IsSuccessful reports whether the code signals an accepted request
+func (c ResultCode) IsSuccessful() bool {
+	return c == ResultCodeSuccess || c == ResultCodeSuccessGateway
+}
@@ -0,0 +1,57 @@
+package payriff
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout bounds each attempt of an API call unless Config.Timeout
+// or Config.HTTPClient is set
+const DefaultTimeout = 30 * time.Second
+
+// defaultClient is used by helpers whose Client is unset, so a hanging
+// peer cannot block them forever the way http.DefaultClient would
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// TransportTimeouts bound the phases of a connection. They apply to the
+// transport the SDK creates, not to Config.HTTPClient or Config.Transport.
+// Zero values keep the defaults of http.DefaultTransport
+type TransportTimeouts struct {
+	// Dial bounds establishing the TCP connection
+	Dial time.Duration
+	// TLSHandshake bounds the TLS handshake
+	TLSHandshake time.Duration
+	// ResponseHeader bounds the wait for the response headers after the
+	// request was written
+	ResponseHeader time.Duration
+}
+
+// transport returns a copy of http.DefaultTransport with the timeouts
+// applied, or nil when none is set
+func (t TransportTimeouts) transport() http.RoundTripper {
+	if t == (TransportTimeouts{}) {
+		return nil
+	}
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil
+	}
+
+	tr := base.Clone()
+	if t.Dial > 0 {
+		tr.DialContext = (&net.Dialer{Timeout: t.Dial, KeepAlive: 30 * time.Second}).DialContext
+	}
+	if t.TLSHandshake > 0 {
+		tr.TLSHandshakeTimeout = t.TLSHandshake
+	}
+	if t.ResponseHeader > 0 {
+		tr.ResponseHeaderTimeout = t.ResponseHeader
+	}
+	return tr
+}
+
+// WithDeadline bounds the call, including retries, by an absolute time
+func WithDeadline(deadline time.Time) RequestOption {
+	return func(o *RequestOptions) { o.Deadline = deadline }
+}
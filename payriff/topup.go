@@ -0,0 +1,53 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// TopupRequest transfers funds from the merchant account to a card. Set
+// either CardNumber or CardUUID
+type TopupRequest struct {
+	CardNumber  string   `json:"cardNumber,omitempty"`
+	CardUUID    CardUUID `json:"cardUuid,omitempty"`
+	Amount      Amount   `json:"amount"`
+	Currency    Currency `json:"currency,omitempty"`
+	Description string   `json:"description"`
+}
+
+// TopupPayload is the result of a transfer to a card
+type TopupPayload struct {
+	OrderID       OrderID  `json:"orderId"`
+	TransactionID int64    `json:"transactionId"`
+	Amount        float64  `json:"amount"`
+	CurrencyType  Currency `json:"currencyType"`
+	PaymentStatus Status   `json:"paymentStatus"`
+	ResponseCode  string   `json:"responseCode,omitempty"`
+}
+
+// Topup transfers funds to a card, e.g. for payouts
+func (s *SDK) Topup(ctx context.Context, req TopupRequest) (*ApiResponse[TopupPayload], error) {
+	if (req.CardNumber == "") == (req.CardUUID == "") {
+		return nil, errors.New("payriff: topup requires either a card number or a card UUID")
+	}
+	if !req.Amount.IsPositive() {
+		return nil, errors.New("payriff: topup amount must be positive")
+	}
+
+	// Apply defaults if values are not provided
+	if req.Currency == "" {
+		req.Currency = s.defaultCurrency
+	}
+
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.makeRequest(ctx, "/topup", http.MethodPost, ScopeSecret, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse[TopupPayload](s, "POST /topup", resp)
+}
@@ -0,0 +1,136 @@
+package payriff
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidConfig is matched by every error returned by Config.Validate
+var ErrInvalidConfig = errors.New("payriff: invalid config")
+
+// ConfigError reports a problem with one Config field
+type ConfigError struct {
+	Field   string
+	Problem string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("payriff: invalid config: %s %s", e.Field, e.Problem)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// NewSDKStrict creates an SDK like NewSDK, but returns the problems found
+// by Config.Validate instead of failing on the first API call
+func NewSDKStrict(config Config) (*SDK, error) {
+	s := NewSDK(config)
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate checks the configuration after profiles, environment variables
+// and defaults are applied. Every problem is returned as a *ConfigError,
+// joined with errors.Join
+func (c Config) Validate() error {
+	return NewSDK(c).validate()
+}
+
+func (s *SDK) validate() error {
+	var errs []error
+	configErrs := []error{s.configErr}
+	if joined, ok := s.configErr.(interface{ Unwrap() []error }); ok {
+		configErrs = joined.Unwrap()
+	}
+	for _, err := range configErrs {
+		var configErr *ConfigError
+		if errors.As(err, &configErr) {
+			errs = append(errs, configErr)
+		} else if err != nil {
+			errs = append(errs, &ConfigError{Field: "Profile", Problem: err.Error()})
+		}
+	}
+
+	if s.readOnly {
+		if s.publicKey == "" {
+			errs = append(errs, &ConfigError{Field: "PublicKey", Problem: "is empty; ReadOnly needs the public key"})
+		}
+	} else if s.auth == nil && s.secretKey == "" && len(s.keys) == 0 {
+		errs = append(errs, &ConfigError{Field: "SecretKey", Problem: "is empty; set it, Keys, Auth or PAYRIFF_SECRET_KEY, or ReadOnly with PublicKey"})
+	}
+	if problem := checkKey(s.secretKey); problem != "" {
+		errs = append(errs, &ConfigError{Field: "SecretKey", Problem: problem})
+	}
+	if problem := checkKey(s.publicKey); problem != "" {
+		errs = append(errs, &ConfigError{Field: "PublicKey", Problem: problem})
+	}
+	for i, key := range s.keys {
+		if key.Secret == "" {
+			errs = append(errs, &ConfigError{Field: fmt.Sprintf("Keys[%d].Secret", i), Problem: "is empty"})
+		} else if problem := checkKey(key.Secret); problem != "" {
+			errs = append(errs, &ConfigError{Field: fmt.Sprintf("Keys[%d].Secret", i), Problem: problem})
+		}
+	}
+
+	if s.signing != nil && s.signing.Secret == "" && s.auth != nil {
+		errs = append(errs, &ConfigError{Field: "Signing.Secret", Problem: "is empty; Auth requests have no API key to sign with"})
+	}
+
+	if u, err := url.Parse(s.baseURL); err != nil {
+		errs = append(errs, &ConfigError{Field: "BaseURL", Problem: fmt.Sprintf("does not parse: %v", err)})
+	} else if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		errs = append(errs, &ConfigError{Field: "BaseURL", Problem: fmt.Sprintf("%q is not an absolute http(s) URL", s.baseURL)})
+	}
+
+	if s.defaultCallbackURL != "" {
+		if problem := checkCallbackURL(s.defaultCallbackURL); problem != "" {
+			errs = append(errs, &ConfigError{Field: "DefaultCallbackURL", Problem: problem})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkKey returns a problem with a set API key, or ""
+func checkKey(key string) string {
+	switch {
+	case key == "":
+		return ""
+	case strings.TrimSpace(key) != key:
+		return "has leading or trailing whitespace"
+	case strings.ContainsAny(key, " \t\r\n"):
+		return "contains whitespace; pass the bare key without a scheme such as Bearer"
+	}
+	return ""
+}
+
+// checkCallbackURL requires HTTPS, except for local development hosts
+func checkCallbackURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Sprintf("does not parse: %v", err)
+	}
+	if u.Host == "" {
+		return fmt.Sprintf("%q is not an absolute URL", raw)
+	}
+	if u.Scheme == "https" {
+		return ""
+	}
+	if u.Scheme == "http" && isLocalHost(u.Hostname()) {
+		return ""
+	}
+	return fmt.Sprintf("%q must use https", raw)
+}
+
+func isLocalHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,43 @@
+package payriff
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultAPIVersion is the gateway API version used when none is configured
+const DefaultAPIVersion = "v3"
+
+// versionSuffix matches a trailing version segment such as /v3
+var versionSuffix = regexp.MustCompile(`/(v[0-9]+)/?$`)
+
+// splitBaseURL separates a trailing version segment from baseURL, so a
+// BaseURL of https://api.payriff.com/api/v3 keeps working
+func splitBaseURL(baseURL string) (root, version string) {
+	if m := versionSuffix.FindStringSubmatchIndex(baseURL); m != nil {
+		return baseURL[:m[0]], baseURL[m[2]:m[3]]
+	}
+	return strings.TrimSuffix(baseURL, "/"), ""
+}
+
+// apiURL returns the versioned URL for endpoint
+func (s *SDK) apiURL(endpoint string) string {
+	if s.apiVersion == "" {
+		return s.baseURL + endpoint
+	}
+	return s.baseURL + "/" + s.apiVersion + endpoint
+}
+
+// WithAPIVersion returns a copy of the SDK that addresses the given API
+// version, for endpoints that remain on an older version or have moved to a
+// newer one. The copy shares configuration, caches and connections with s
+func (s *SDK) WithAPIVersion(version string) *SDK {
+	c := *s
+	c.apiVersion = version
+	return &c
+}
+
+// APIVersion returns the API version the SDK addresses
+func (s *SDK) APIVersion() string {
+	return s.apiVersion
+}
@@ -0,0 +1,80 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WaitOptions configures WaitForOrder
+type WaitOptions struct {
+	// Interval is the delay before the second poll, doubling up to
+	// MaxInterval. Defaults to one second
+	Interval time.Duration
+	// MaxInterval defaults to 15 seconds
+	MaxInterval time.Duration
+	// Until reports whether polling can stop, defaults to Status.IsTerminal
+	Until func(Status) bool
+	// OnPoll receives every fresh order state, e.g. for progress updates
+	OnPoll func(OrderInfo)
+}
+
+// WaitForOrder polls GetOrderInfo with backoff until the order reaches a
+// terminal status, for integrations that cannot receive callbacks. Network
+// errors, gateway outages and maintenance are polled through, waiting at
+// least the maintenance Retry-After; a rejected lookup ends the
+// wait with an *APIError. When ctx ends first, the last known state is
+// returned with the context's error
+func (s *SDK) WaitForOrder(ctx context.Context, orderID OrderID, opts WaitOptions) (*OrderInfo, error) {
+	interval, maxInterval := opts.Interval, opts.MaxInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if maxInterval <= 0 {
+		maxInterval = 15 * time.Second
+	}
+	until := opts.Until
+	if until == nil {
+		until = Status.IsTerminal
+	}
+
+	var last *OrderInfo
+	delay := interval
+	for {
+		wait := delay
+		resp, err := s.GetOrderInfoContext(ctx, orderID)
+		var maintenance *MaintenanceError
+		switch {
+		case errors.As(err, &maintenance):
+			wait = max(wait, maintenance.RetryAfter)
+		case err != nil:
+			if !isTransient(err) && ctx.Err() == nil {
+				return last, fmt.Errorf("failed to poll order %s: %w", orderID, err)
+			}
+		case !resp.IsSuccessful():
+			return last, resp.Err()
+		case !resp.Stale:
+			info := resp.Payload
+			last = &info
+			if opts.OnPoll != nil {
+				s.runHook(func() { opts.OnPoll(info) })
+			}
+			if until(info.PaymentStatus) {
+				return last, nil
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if last != nil {
+				return last, fmt.Errorf("order %s still %s: %w", orderID, last.PaymentStatus, ctx.Err())
+			}
+			return nil, fmt.Errorf("failed to poll order %s: %w", orderID, ctx.Err())
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxInterval)
+	}
+}
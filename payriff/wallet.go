@@ -0,0 +1,88 @@
+package payriff
+
+import "sort"
+
+// Balance is the merchant balance in one currency
+type Balance struct {
+	Currency Currency `json:"currency"`
+	// Available can be paid out
+	Available Amount `json:"available"`
+	// Pending is captured but not yet settled
+	Pending Amount `json:"pending"`
+	// Reserved is held for pre-authorizations, rolling reserves or disputes
+	Reserved Amount `json:"reserved"`
+}
+
+// Total returns the sum of the available, pending and reserved amounts
+func (b Balance) Total() Amount {
+	return b.Available.Add(b.Pending).Add(b.Reserved)
+}
+
+// WalletSummary is the per-currency position of a merchant operating in
+// several currencies. All sums use exact minor-unit arithmetic
+type WalletSummary struct {
+	// Balances holds one entry per currency, sorted by currency
+	Balances []Balance
+}
+
+// SummarizeWallet merges balances, e.g. of several terminals or accounts,
+// into one entry per currency. Orders that the balances do not reflect yet
+// are added in their settlement currency: approved orders to Pending and
+// pre-authorized ones to Reserved. Other orders are ignored
+func SummarizeWallet(balances []Balance, unsettled []OrderInfo) WalletSummary {
+	byCurrency := make(map[Currency]*Balance)
+	entry := func(c Currency) *Balance {
+		b, ok := byCurrency[c]
+		if !ok {
+			b = &Balance{Currency: c}
+			byCurrency[c] = b
+		}
+		return b
+	}
+
+	for _, b := range balances {
+		e := entry(b.Currency)
+		e.Available = e.Available.Add(b.Available)
+		e.Pending = e.Pending.Add(b.Pending)
+		e.Reserved = e.Reserved.Add(b.Reserved)
+	}
+	for _, o := range unsettled {
+		amount, currency := o.Settlement()
+		switch o.PaymentStatus {
+		case StatusApproved:
+			e := entry(currency)
+			e.Pending = e.Pending.Add(AmountOf(amount))
+		case StatusPreAuthApproved:
+			e := entry(currency)
+			e.Reserved = e.Reserved.Add(AmountOf(amount))
+		}
+	}
+
+	summary := WalletSummary{Balances: make([]Balance, 0, len(byCurrency))}
+	for _, b := range byCurrency {
+		summary.Balances = append(summary.Balances, *b)
+	}
+	sort.Slice(summary.Balances, func(i, j int) bool {
+		return summary.Balances[i].Currency < summary.Balances[j].Currency
+	})
+	return summary
+}
+
+// Balance returns the entry for currency, zero when the merchant holds none
+func (w WalletSummary) Balance(currency Currency) Balance {
+	for _, b := range w.Balances {
+		if b.Currency == currency {
+			return b
+		}
+	}
+	return Balance{Currency: currency}
+}
+
+// Currencies returns the currencies in the summary
+func (w WalletSummary) Currencies() []Currency {
+	currencies := make([]Currency, len(w.Balances))
+	for i, b := range w.Balances {
+		currencies[i] = b.Currency
+	}
+	return currencies
+}
@@ -0,0 +1,80 @@
+package payriff
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// CallbackHandler handles a parsed callback
+type CallbackHandler func(ctx context.Context, e *CallbackEvent) error
+
+// WebhookHandler parses callbacks, verifies them and dispatches them by
+// payment status. It is an http.Handler built on Processor, so deliveries
+// are also deduplicated; use Handle as a Processor.Handler to customize
+// the processing. Statuses without a handler are acknowledged
+type WebhookHandler struct {
+	Verifiers []DeliveryVerifier
+	// Store defaults to a MemoryEventStore
+	Store EventStore
+
+	// OnApproved handles APPROVED and PREAUTH_APPROVED
+	OnApproved CallbackHandler
+	OnDeclined CallbackHandler
+	// OnCanceled handles CANCELED and EXPIRED
+	OnCanceled CallbackHandler
+	// OnRefunded handles REFUNDED, PARTIAL_REFUND and REVERSE
+	OnRefunded CallbackHandler
+	// OnOther handles any other status
+	OnOther CallbackHandler
+	// OnError receives panics recovered from handlers
+	OnError func(error)
+
+	once      sync.Once
+	processor *Processor
+}
+
+// Handle parses a delivery and runs the handler for its status, with the
+// event's order scoped to ctx as with WithOrder. Bodies that are not valid
+// callbacks are rejected
+func (h *WebhookHandler) Handle(ctx context.Context, d *Delivery) error {
+	event, err := d.ParseCallback()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDeliveryRejected, err)
+	}
+
+	handler := h.OnOther
+	switch event.PaymentStatus {
+	case StatusApproved, StatusPreAuthApproved:
+		handler = h.OnApproved
+	case StatusDeclined:
+		handler = h.OnDeclined
+	case StatusCanceled, StatusExpired:
+		handler = h.OnCanceled
+	case StatusRefunded, StatusPartialRefund, StatusReverse:
+		handler = h.OnRefunded
+	}
+	if handler == nil {
+		return nil
+	}
+	ctx = WithOrder(ctx, event.OrderID, Money{Amount: AmountOf(event.Amount), Currency: event.Currency})
+	return handler(ctx, event)
+}
+
+// ServeHTTP implements http.Handler
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.once.Do(func() {
+		store := h.Store
+		if store == nil {
+			store = &MemoryEventStore{}
+		}
+		h.processor = &Processor{
+			Verifiers: h.Verifiers,
+			Store:     store,
+			Handler:   h.Handle,
+			OnError:   h.OnError,
+		}
+	})
+	h.processor.ServeHTTP(w, r)
+}
@@ -0,0 +1,85 @@
+package payriff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WooCommerceCart is a reference CartAdapter for the cart returned by the
+// WooCommerce Store API (GET /wp-json/wc/store/v1/cart). Decode the response
+// into it and pass it to CreateOrderFromCart
+type WooCommerceCart struct {
+	// Reference is the merchant's order number, not part of the cart
+	Reference string `json:"-"`
+
+	Items []struct {
+		Name     string `json:"name"`
+		SKU      string `json:"sku"`
+		Quantity int    `json:"quantity"`
+		Prices   struct {
+			Price             string `json:"price"`
+			CurrencyMinorUnit int    `json:"currency_minor_unit"`
+		} `json:"prices"`
+	} `json:"items"`
+	Totals struct {
+		TotalPrice        string `json:"total_price"`
+		CurrencyCode      string `json:"currency_code"`
+		CurrencyMinorUnit int    `json:"currency_minor_unit"`
+	} `json:"totals"`
+	BillingAddress struct {
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Email     string `json:"email"`
+		Phone     string `json:"phone"`
+	} `json:"billing_address"`
+}
+
+// PaymentCart implements CartAdapter
+func (w *WooCommerceCart) PaymentCart(ctx context.Context) (Cart, error) {
+	total, err := storeAmount(w.Totals.TotalPrice, w.Totals.CurrencyMinorUnit)
+	if err != nil {
+		return Cart{}, fmt.Errorf("failed to read cart total: %w", err)
+	}
+
+	cart := Cart{
+		Reference: w.Reference,
+		Total:     total,
+		Currency:  Currency(w.Totals.CurrencyCode),
+		Customer: Customer{
+			FullName: strings.TrimSpace(w.BillingAddress.FirstName + " " + w.BillingAddress.LastName),
+			Email:    w.BillingAddress.Email,
+			Phone:    w.BillingAddress.Phone,
+		},
+	}
+	for _, item := range w.Items {
+		price, err := storeAmount(item.Prices.Price, item.Prices.CurrencyMinorUnit)
+		if err != nil {
+			return Cart{}, fmt.Errorf("failed to read price of %s: %w", item.Name, err)
+		}
+		cart.Items = append(cart.Items, CartItem{SKU: item.SKU, Name: item.Name, Quantity: item.Quantity, UnitPrice: price})
+	}
+	return cart, nil
+}
+
+// storeAmount converts a Store API price, given in minor units with
+// minorUnit decimals, into an Amount
+func storeAmount(value string, minorUnit int) (Amount, error) {
+	if value == "" || !isDigits(value) {
+		return Amount{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	if minorUnit < 0 {
+		return Amount{}, errors.New("payriff: negative currency minor unit")
+	}
+	if minorUnit == 0 {
+		return ParseAmount(value)
+	}
+
+	value = strings.Repeat("0", max(0, minorUnit+1-len(value))) + value
+	whole, frac := value[:len(value)-minorUnit], strings.TrimRight(value[len(value)-minorUnit:], "0")
+	if frac == "" {
+		return ParseAmount(whole)
+	}
+	return ParseAmount(whole + "." + frac)
+}